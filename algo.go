@@ -1,27 +1,164 @@
 package main
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// SolutionFn is the callback function type used by the implementations to report each solution found.
+// It may be called concurrently by the implementations using multiple Goroutines.
+type SolutionFn func(solution []int)
+
+// Solver is the interface implemented by all the algorithm implementations.
+type Solver interface {
+	// Solve searches for the solutions of the board, reports each of them using the onSolution callback and
+	// returns the best one found. The search must stop as soon as possible once the context is done, in which case
+	// the best solution found so far is returned along with the context error.
+	Solve(ctx context.Context, board *Board, onSolution SolutionFn) ([]int, error)
+}
 
 // AlgorithmFn is the function type that will be used by all the implementations.
-type AlgorithmFn func(board *Board, solutions chan []int, done chan void, debug bool) ([]int, error)
+type AlgorithmFn func(ctx context.Context, board *Board, onSolution SolutionFn, config *SolverConfig) ([]int, error)
+
+// SolverConfig contains the configuration parameters shared by all the implementations.
+type SolverConfig struct {
+	// Debug flag to activate some logs.
+	debug bool
+
+	// Number of worker Goroutines used by the parallel implementations, 0 means one per CPU core.
+	workers int
+
+	// Whether to verify the board configuration of the cache entries to detect the hash collisions.
+	cacheVerify bool
+
+	// Size in megabytes of the transposition tables, 0 means a default size depending on the board size.
+	cacheMb int
+
+	// Initial number of board configurations kept at each step by the beam search, 0 means the default width.
+	beamWidth int
+
+	// Name of the board evaluation function used by the beam search, see boardEvaluations.
+	beamEvaluation string
+
+	// Seed of the random number generator used by the Monte Carlo Tree Search, 0 means a time based seed.
+	seed int64
+
+	// Statistics of the execution updated by the implementations, nil to discard them.
+	stats *SolverStats
+
+	// Function rendering the boards of the debug trace printed by the linear implementations, nil to disable it.
+	boardRenderer BoardRendererFn
+
+	// File in which the deep search periodically saves its state, empty to disable it, see DeepSearchCheckpoint.
+	// The interval between two checkpoints, 0 means the default one, and whether the transposition table is saved.
+	checkpointFile     string
+	checkpointInterval time.Duration
+	checkpointCache    bool
+
+	// Whether the deep search is resumed from the state saved in the checkpoint file, if it exists.
+	resume bool
+
+	// A known solution of the board, e.g. from the solution database, used as initial upper bound of the step count by
+	// the implementations computing one, nil if unknown.
+	knownSolution []int
+}
+
+// SolverStats contains the statistics of an implementation execution, updated while it's running.
+// It's safe for concurrent use by multiple Goroutines and its methods can be called on a nil pointer.
+type SolverStats struct {
+	// Number of board configurations evaluated.
+	evaluationCounter atomic.Int64
+
+	// Whether the best solution found has been proven optimal.
+	optimalProven atomic.Bool
+}
+
+// Count a board configuration evaluation.
+func (stats *SolverStats) addEvaluation() {
+	if stats != nil {
+		stats.evaluationCounter.Add(1)
+	}
+}
+
+// Record that the best solution found has been proven optimal.
+func (stats *SolverStats) setOptimalProven() {
+	if stats != nil {
+		stats.optimalProven.Store(true)
+	}
+}
+
+// Returns the number of board configurations evaluated.
+func (stats *SolverStats) getEvaluationCount() int64 {
+	if stats == nil {
+		return 0
+	}
+	return stats.evaluationCounter.Load()
+}
+
+// Returns whether the best solution found has been proven optimal.
+func (stats *SolverStats) isOptimalProven() bool {
+	return stats != nil && stats.optimalProven.Load()
+}
+
+// Solver implementation executing an algorithm function with its configuration.
+type algorithmSolver struct {
+	algorithmFn AlgorithmFn
+	config      *SolverConfig
+}
+
+func (solver *algorithmSolver) Solve(ctx context.Context, board *Board, onSolution SolutionFn) ([]int, error) {
+	return solver.algorithmFn(ctx, board, onSolution, solver.config)
+}
+
+// Returns the sorted list of the available algorithm implementation names.
+func getImplementationNames() []string {
+	names := make([]string, 0, len(implementations))
+	for name := range implementations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Returns a new solver executing the algorithm implementation specified by its name.
+func newSolver(impl string, config *SolverConfig) (Solver, error) {
+	algorithmFn, exists := implementations[impl]
+	if !exists {
+		return nil, fmt.Errorf("invalid algorithm implementation %q, available ones are %v", impl, getImplementationNames())
+	}
+
+	return &algorithmSolver{
+		algorithmFn: algorithmFn,
+		config:      config,
+	}, nil
+}
 
 // ColorPickerFn is the function type returning the color to play at the next step.
 type ColorPickerFn func(board *Board) int
 
 // Linear implementation using the provided color picker function to select the color to play at the next step.
-func linearImpl(board *Board, solutions chan []int, done chan void, colorPickerFn ColorPickerFn, debug bool) ([]int, error) {
-	var solution []int
+// If a board renderer function is provided, the board is printed to stderr at each step.
+func linearImpl(ctx context.Context, board *Board, onSolution SolutionFn, colorPickerFn ColorPickerFn, boardRendererFn BoardRendererFn) ([]int, error) {
+	solution := []int{}
 
 	// Loop until the board is solved.
 	for {
-		// Print the board status as CSV.
-		if debug {
-			fmt.Printf("Step #%d (color %d)\n", len(solution), board.cells[0])
-			boardCsv, err := serializeBoardToCsv(board)
+		// Check if the execution must be stopped.
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Print the board status.
+		if boardRendererFn != nil {
+			boardRendering, err := boardRendererFn(board)
 			if err != nil {
-				return nil, fmt.Errorf("unable to serialize the board as CSV: %w", err)
+				return nil, fmt.Errorf("unable to render the board: %w", err)
 			}
-			fmt.Println(boardCsv)
+			fmt.Fprintf(os.Stderr, "Step #%d (color %d)\n%s\n", len(solution), board.cells[0], boardRendering)
 		}
 
 		// Check if the board is solved.
@@ -39,13 +176,8 @@ func linearImpl(board *Board, solutions chan []int, done chan void, colorPickerF
 		solution = append(solution, color)
 	}
 
-	// Push the new solution to the channel.
-	solutions <- solution
-
-	// Notify that the execution is finished.
-	if done != nil {
-		done <- void{}
-	}
+	// Report the new solution.
+	onSolution(solution)
 
 	return solution, nil
 }
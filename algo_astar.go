@@ -0,0 +1,175 @@
+package main
+
+import (
+	"container/heap"
+	"context"
+	"github.com/rs/zerolog/log"
+)
+
+// Implementation using the A* algorithm on the region graph of the board to find the optimal solution.
+// The boards are evaluated by ascending minimum total step count, computed with an admissible heuristic, so the first
+// solved board evaluated is an optimal solution.
+func aStar(execCtx context.Context, board *Board, onSolution SolutionFn, config *SolverConfig) ([]int, error) {
+	// First compute a "good" solution to have an initial step count that will be used to prune the graph search.
+	initialSolution, err := computeInitialSolution(execCtx, board, onSolution, config.knownSolution)
+	if err != nil {
+		return nil, err
+	}
+
+	// Initialize the open set with the initial board.
+	graphBoard := NewGraphBoard(newRegionGraph(board))
+	root := &AStarNode{
+		board:        graphBoard,
+		id:           graphBoard.getId(),
+		minStepCount: computeMinStepCount(graphBoard),
+	}
+	openSet := &AStarQueue{root}
+
+	// Map of the smallest step count at which each board configuration has been reached, by board ID.
+	bestStepCounts := map[string]int{root.id: 0}
+
+	// Evaluate the boards by ascending minimum total step count.
+	evaluationCounter := 0
+	for openSet.Len() > 0 {
+		// Check if the execution must be stopped.
+		if err := execCtx.Err(); err != nil {
+			return initialSolution, err
+		}
+
+		// Get the most promising board.
+		node := heap.Pop(openSet).(*AStarNode)
+		if node.stepCount > bestStepCounts[node.id] {
+			// This board configuration has been reached with a smaller step count after being pushed, skip it.
+			continue
+		}
+
+		// Print debug stats.
+		config.stats.addEvaluation()
+		evaluationCounter++
+		if config.debug && evaluationCounter%100_000 == 0 {
+			log.Debug().
+				Int("evaluation", evaluationCounter).
+				Int("min-step-count", node.minStepCount).
+				Int("open-set-size", openSet.Len()).
+				Int("known-boards", len(bestStepCounts)).
+				Msg("progress")
+		}
+
+		// Check if the board is solved, the solution is then optimal.
+		if node.board.isSolved() {
+			solution := node.getSteps()
+			if len(solution) < len(initialSolution) {
+				onSolution(solution)
+			}
+			log.Info().Int("step-count", len(solution)).Msg("optimal solution found")
+			config.stats.setOptimalProven()
+			return solution, nil
+		}
+
+		// Try all the colors in the frontier.
+		stepCount := node.stepCount + 1
+		for _, color := range node.board.getColorsInFrontier() {
+			// Clone and update the board.
+			boardCopy := node.board.clone()
+			boardCopy.playStep(color)
+
+			// Check if this board configuration has already been reached with a lower or equal step count.
+			id := boardCopy.getId()
+			previousStepCount, alreadyReached := bestStepCounts[id]
+			if alreadyReached && previousStepCount <= stepCount {
+				continue
+			}
+
+			// Check if we can still hope to improve the initial solution.
+			minStepCount := stepCount + computeMinStepCount(boardCopy)
+			if minStepCount >= len(initialSolution) {
+				continue
+			}
+
+			// Add the board to the open set.
+			bestStepCounts[id] = stepCount
+			heap.Push(openSet, &AStarNode{
+				board:        boardCopy,
+				id:           id,
+				parent:       node,
+				color:        color,
+				stepCount:    stepCount,
+				minStepCount: minStepCount,
+			})
+		}
+	}
+
+	// No solution shorter than the initial one exists, it is thus optimal.
+	log.Info().Int("step-count", len(initialSolution)).Msg("optimal solution found")
+	config.stats.setOptimalProven()
+	return initialSolution, nil
+}
+
+// Returns an admissible lower bound of the number of steps needed to solve the board, i.e. the maximum of:
+//   - the number of remaining colors, as each step can only remove one color from the board
+//   - the eccentricity of the completed area in the region graph, as each step can only merge the adjacent regions
+func computeMinStepCount(board *GraphBoard) int {
+	remainingColorCount := board.getRemainingColorCount()
+	eccentricity := board.getEccentricity()
+	if eccentricity > remainingColorCount {
+		return eccentricity
+	}
+	return remainingColorCount
+}
+
+// AStarNode is a board configuration reached by the A* implementation.
+type AStarNode struct {
+	// The board configuration and its ID.
+	board *GraphBoard
+	id    string
+
+	// The node from which this board configuration has been reached and the color played to reach it.
+	parent *AStarNode
+	color  int
+
+	// The number of steps played to reach this board configuration.
+	stepCount int
+
+	// The minimum total step count of a solution going through this board configuration.
+	minStepCount int
+}
+
+// Returns the steps played to reach the board configuration of a node.
+func (node *AStarNode) getSteps() []int {
+	steps := make([]int, node.stepCount)
+	for current := node; current.parent != nil; current = current.parent {
+		steps[current.stepCount-1] = current.color
+	}
+	return steps
+}
+
+// AStarQueue is a priority queue of nodes ordered by ascending minimum total step count, then by descending step
+// count to evaluate first the nodes closer to a solution. It implements heap.Interface.
+type AStarQueue []*AStarNode
+
+func (queue AStarQueue) Len() int {
+	return len(queue)
+}
+
+func (queue AStarQueue) Less(i, j int) bool {
+	if queue[i].minStepCount != queue[j].minStepCount {
+		return queue[i].minStepCount < queue[j].minStepCount
+	}
+	return queue[i].stepCount > queue[j].stepCount
+}
+
+func (queue AStarQueue) Swap(i, j int) {
+	queue[i], queue[j] = queue[j], queue[i]
+}
+
+func (queue *AStarQueue) Push(x any) {
+	*queue = append(*queue, x.(*AStarNode))
+}
+
+func (queue *AStarQueue) Pop() any {
+	old := *queue
+	node := old[len(old)-1]
+	old[len(old)-1] = nil
+	*queue = old[:len(old)-1]
+	return node
+}
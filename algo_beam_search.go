@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"sort"
+)
+
+// Default number of board configurations kept at each step by the beam search implementation.
+const defaultBeamWidth = 64
+
+// Maximum memory in bytes used by the board configurations of a beam search step, it limits the beam width.
+const maxBeamMemory = 512 * 1024 * 1024
+
+// BoardEvaluationFn is the function type returning the score of a board configuration, the higher the better.
+type BoardEvaluationFn func(board *Board) float64
+
+// Available board evaluation functions.
+var boardEvaluations = map[string]BoardEvaluationFn{
+	"area":     evaluateCompletedArea,
+	"frontier": evaluateFrontierSize,
+	"colors":   evaluateRemainingColors,
+}
+
+// Returns the number of cells in the completed area.
+func evaluateCompletedArea(board *Board) float64 {
+	return float64(board.completedCount)
+}
+
+// Returns the number of cells in the frontier, i.e. the boards with the largest choice of areas to merge are better.
+func evaluateFrontierSize(board *Board) float64 {
+	return float64(board.frontierCells.count())
+}
+
+// Returns the opposite of the number of remaining colors, the ties being broken by the number of cells in the
+// completed area.
+func evaluateRemainingColors(board *Board) float64 {
+	return float64(board.completedCount - board.getRemainingColorCount()*len(board.cells))
+}
+
+// Implementation keeping at each step only the best board configurations, according to an evaluation function.
+// The search is executed again with a beam width doubled each time, until the beam has never been truncated (the
+// search was then exhaustive and the solution found is optimal) or until reaching the memory limit.
+func beamSearch(ctx context.Context, board *Board, onSolution SolutionFn, config *SolverConfig) ([]int, error) {
+	// Get the configuration.
+	evaluationName := config.beamEvaluation
+	if evaluationName == "" {
+		evaluationName = "area"
+	}
+	evaluationFn, exists := boardEvaluations[evaluationName]
+	if !exists {
+		return nil, fmt.Errorf("invalid beam search evaluation function %q", evaluationName)
+	}
+
+	beamWidth := config.beamWidth
+	if beamWidth <= 0 {
+		beamWidth = defaultBeamWidth
+	}
+	boardMemory := len(board.cells)*8 + len(board.completedCells)*8*2
+	maxBeamWidth := maxBeamMemory / (boardMemory * board.nbColors)
+
+	// Execute the searches with an increasing width.
+	var bestSolution []int = nil
+	for {
+		solution, truncated, err := doBeamSearch(ctx, board, beamWidth, evaluationFn, config.stats)
+		if err != nil {
+			return bestSolution, err
+		}
+
+		// Check if we improved the best solution.
+		if bestSolution == nil || len(solution) < len(bestSolution) {
+			bestSolution = solution
+			onSolution(solution)
+		}
+		log.Debug().
+			Int("beam-width", beamWidth).
+			Int("step-count", len(solution)).
+			Bool("truncated", truncated).
+			Msg("beam search finished")
+
+		// Check if the search must be executed again with a larger width.
+		if !truncated {
+			log.Info().Int("step-count", len(bestSolution)).Msg("optimal solution found")
+			config.stats.setOptimalProven()
+			break
+		}
+		if beamWidth >= maxBeamWidth {
+			break
+		}
+		beamWidth *= 2
+		if beamWidth > maxBeamWidth {
+			beamWidth = maxBeamWidth
+		}
+	}
+
+	return bestSolution, nil
+}
+
+// BeamSearchState is a board configuration kept by the beam search implementation.
+type BeamSearchState struct {
+	// The board configuration.
+	board *Board
+
+	// The steps played to reach this board configuration.
+	steps []int
+
+	// The score of the board configuration.
+	score float64
+}
+
+// Execute a beam search with the specified width and return the solution found along with whether some board
+// configurations have been discarded.
+func doBeamSearch(ctx context.Context, board *Board, beamWidth int, evaluationFn BoardEvaluationFn, stats *SolverStats) ([]int, bool, error) {
+	states := []*BeamSearchState{{board: board, steps: []int{}}}
+	if board.isSolved() {
+		return states[0].steps, false, nil
+	}
+
+	truncated := false
+	for {
+		// Compute all the board configurations reachable from the current ones, without duplicates.
+		var nextStates []*BeamSearchState
+		processedBoards := make(map[uint64][]*Board)
+		for _, state := range states {
+			// Check if the execution must be stopped.
+			if err := ctx.Err(); err != nil {
+				return nil, truncated, err
+			}
+
+			for _, color := range state.board.getColorsInFrontier() {
+				// Clone and update the board.
+				boardCopy := state.board.clone()
+				boardCopy.playStep(color)
+
+				// The boards with the same hash are compared, so that a collision never discards a configuration.
+				if isBoardProcessed(processedBoards[boardCopy.hash], boardCopy) {
+					continue
+				}
+				processedBoards[boardCopy.hash] = append(processedBoards[boardCopy.hash], boardCopy)
+				stats.addEvaluation()
+
+				// Copy the steps and append the current color.
+				stepsCopy := make([]int, len(state.steps)+1)
+				copy(stepsCopy, state.steps)
+				stepsCopy[len(stepsCopy)-1] = color
+
+				// Check if the board is solved, all the configurations having the same step count it's a best one.
+				if boardCopy.isSolved() {
+					return stepsCopy, truncated, nil
+				}
+
+				nextStates = append(nextStates, &BeamSearchState{
+					board: boardCopy,
+					steps: stepsCopy,
+					score: evaluationFn(boardCopy),
+				})
+			}
+		}
+
+		// Keep only the best board configurations.
+		if len(nextStates) > beamWidth {
+			sort.SliceStable(nextStates, func(i, j int) bool {
+				return nextStates[i].score > nextStates[j].score
+			})
+			nextStates = nextStates[:beamWidth]
+			truncated = true
+		}
+		states = nextStates
+	}
+}
+
+// Returns whether a board has the same configuration as one of the processed boards having the same hash.
+func isBoardProcessed(processedBoards []*Board, board *Board) bool {
+	for _, processedBoard := range processedBoards {
+		if processedBoard.isSameConfiguration(board) {
+			return true
+		}
+	}
+	return false
+}
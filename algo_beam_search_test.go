@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBeamSearch(t *testing.T) {
+	// The beam is widened until the search is exhaustive, the solution is then optimal.
+	solution := testImplementation(t, beamSearch, &SolverConfig{}, "samples/12_12_4-1.csv")
+	if len(solution) != 12 {
+		t.Fatalf("the solution is not optimal, expected=12, actual=%d", len(solution))
+	}
+}
+
+func TestDoBeamSearch(t *testing.T) {
+	for _, testCase := range []struct {
+		inputFile string
+		optimum   int
+	}{
+		{"samples/12_12_4-1.csv", 12},
+		{"samples/12_12_5-1.csv", 14},
+	} {
+		for _, evaluation := range []string{"area", "colors"} {
+			board, err := readInputFile(testCase.inputFile, &InputOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// A single search with the default width is not exhaustive, the solution must be close to the optimal one.
+			solution, _, err := doBeamSearch(context.Background(), board.clone(), defaultBeamWidth, boardEvaluations[evaluation], nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report := verifySolution(board, solution); !report.solved {
+				t.Fatalf("the solution does not solve the board %s, evaluation=%s: %v", testCase.inputFile, evaluation, solution)
+			}
+			if len(solution) > testCase.optimum+2 {
+				t.Fatalf("the solution of %s is too long, evaluation=%s, optimum=%d, actual=%d", testCase.inputFile, evaluation, testCase.optimum, len(solution))
+			}
+		}
+	}
+}
+
+func BenchmarkBeamSearch(b *testing.B) {
+	benchmarkImplementation(b, beamSearch, &SolverConfig{}, "samples/12_12_4-1.csv")
+}
@@ -1,45 +1,111 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github.com/rs/zerolog/log"
-	"math"
 	"runtime"
 	"sync"
 )
 
 // Implementation exploring the space of possibilities with a deep tree search to identify the optimal solution.
-func deepSearch(board *Board, solutions chan []int, done chan void, debug bool) ([]int, error) {
-	// First compute a "good" solution to have an initial step count that will be used to prune the graph search.
-	// It's very probably not the optimal solution, but it's fast to compute.
-	initialSolutionStepCount := computeInitialSolutionStepCount(board, solutions)
-
-	// Evaluate the board and return the best steps solution.
+func deepSearch(execCtx context.Context, board *Board, onSolution SolutionFn, config *SolverConfig) ([]int, error) {
 	ctx := &DeepSearchContext{
-		debug:                 debug,
-		bestSolutionStepCount: initialSolutionStepCount,
-		processedCache:        make(map[string]*DeepSearchCacheEntry),
-		solutions:             solutions,
+		debug:          config.debug,
+		done:           execCtx.Done(),
+		processedCache: newTranspositionTable(getTranspositionTableSizeMb(config.cacheMb, board), config.cacheVerify),
+		onSolution:     onSolution,
+		stats:          config.stats,
+		checkpointer:   newDeepSearchCheckpointer(board, config),
+	}
+
+	// Load the checkpoint to resume from, if any.
+	var checkpoint *DeepSearchCheckpoint = nil
+	if config.resume && ctx.checkpointer != nil {
+		var err error
+		checkpoint, err = ctx.checkpointer.load(ctx.processedCache)
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint == nil {
+			log.Warn().Str("file", config.checkpointFile).Msg("no checkpoint to resume from, starting a new search")
+		} else {
+			log.Info().
+				Int("best", checkpoint.BestStepCount).
+				Int("depth", len(checkpoint.Path)).
+				Bool("completed", checkpoint.Completed).
+				Int64("evaluations", checkpoint.Evaluations).
+				Msg("resuming from the checkpoint")
+		}
 	}
-	solution := evaluateBoard(board, []int{}, ctx)
+
+	if checkpoint != nil {
+		// Resume with the best solution of the checkpoint, the search is already finished if it has been completed.
+		// A better known solution only lowers the bound, the branches explored before the checkpoint remain explored.
+		ctx.bestSolution = checkpoint.BestSolution
+		ctx.bestSolutionStepCount = checkpoint.BestStepCount
+		if config.knownSolution != nil && len(config.knownSolution) < ctx.bestSolutionStepCount {
+			ctx.bestSolution = config.knownSolution
+			ctx.bestSolutionStepCount = len(config.knownSolution)
+		}
+		onSolution(ctx.bestSolution)
+		if checkpoint.Completed {
+			if ctx.checkpointer.verified {
+				ctx.stats.setOptimalProven()
+			}
+			return ctx.bestSolution, nil
+		}
+		if len(checkpoint.Path) > 0 {
+			ctx.resumePath = checkpoint.Path
+		}
+	} else {
+		// First compute a "good" solution to have an initial step count that will be used to prune the graph search.
+		// It's very probably not the optimal solution, but it's fast to compute.
+		initialSolution, err := computeInitialSolution(execCtx, board, onSolution, config.knownSolution)
+		if err != nil {
+			return nil, err
+		}
+		ctx.bestSolution = initialSolution
+		ctx.bestSolutionStepCount = len(initialSolution)
+	}
+
+	// Evaluate the board and return the best steps solution.
+	evaluateBoard(board, []int{}, ctx)
 
 	// Print debug stats.
 	ctx.logStats(true)
 
-	// Notify that the execution is finished.
-	if done != nil {
-		done <- void{}
+	// Check if the search tree has been fully explored, the best solution is then optimal unless a hash collision of
+	// the transposition table pruned a branch, which is only excluded when the entries are verified.
+	completed := execCtx.Err() == nil
+	if completed && config.cacheVerify && (ctx.checkpointer == nil || ctx.checkpointer.verified) {
+		ctx.stats.setOptimalProven()
+	}
+
+	// Save the final state: either the search is completed, or it's resumed from the board whose evaluation has been
+	// stopped. If it has been stopped before reaching the board to resume from, the previous checkpoint is still valid.
+	if ctx.checkpointer != nil && (completed || ctx.stoppedPath != nil) {
+		ctx.checkpointer.save(ctx, ctx.stoppedPath, completed)
 	}
 
-	return solution, nil
+	return ctx.bestSolution, execCtx.Err()
 }
 
-// Compute an initial solution using a fast but not optimal implementation and return the best step count found.
-func computeInitialSolutionStepCount(board *Board, solutions chan []int) int {
-	// The fast implementation has some random parts (map iteration order). Thus, we launch multiple instances
-	// in parallel and return the best one.
+// Compute an initial solution using a fast but not optimal implementation and return the best one found.
+// If a solution of the board is already known, it's reported and returned instead.
+// An error is returned only if the execution has been stopped before any initial solution could be computed.
+func computeInitialSolution(ctx context.Context, board *Board, onSolution SolutionFn, knownSolution []int) ([]int, error) {
+	if knownSolution != nil {
+		log.Info().Int("step-count", len(knownSolution)).Msg("known solution used as initial solution")
+		onSolution(knownSolution)
+		return knownSolution, nil
+	}
+
+	// The fast implementation picks the first color when several ones have the same largest area. Thus, we launch
+	// multiple instances in parallel, all but the first one breaking the ties randomly, and return the best one.
 	var waitGroup sync.WaitGroup
 	numCores := runtime.NumCPU()
-	initialSolutionsStepCounts := make([]int, numCores)
+	initialSolutions := make([][]int, numCores)
 	for i := 0; i < numCores; i++ {
 		waitGroup.Add(1)
 		id := i
@@ -47,39 +113,60 @@ func computeInitialSolutionStepCount(board *Board, solutions chan []int) int {
 			defer waitGroup.Done()
 
 			// Call the fast implementation.
-			solution, err := maximizeStepAreaDeep(board.clone(), solutions, nil, false)
+			colorPickerFn := pickColorWithLargestAreaDeep
+			if id > 0 {
+				colorPickerFn = newRandomizedPickColorWithLargestAreaDeep(int64(id))
+			}
+			solution, err := linearImpl(ctx, board.clone(), onSolution, colorPickerFn, nil)
 			if err != nil {
 				// No solution found, this is unfortunate but not blocking.
 				log.Warn().Err(err).Int("id", id).Msg("unable to compute the initial solution")
-				initialSolutionsStepCounts[id] = math.MaxInt
 			} else {
 				// The initial solution is valid.
-				initialSolutionsStepCounts[id] = len(solution)
+				initialSolutions[id] = solution
 			}
 		}()
 	}
 	waitGroup.Wait()
 
-	// Compute the best initial solution step count.
-	initialSolutionStepCount := math.MaxInt
-	for _, count := range initialSolutionsStepCounts {
-		if count < initialSolutionStepCount {
-			initialSolutionStepCount = count
+	// Get the best initial solution.
+	var initialSolution []int = nil
+	for _, solution := range initialSolutions {
+		if solution != nil && (initialSolution == nil || len(solution) < len(initialSolution)) {
+			initialSolution = solution
 		}
 	}
-	log.Info().Int("step-count", initialSolutionStepCount).Msg("initial solution found")
+	if initialSolution == nil {
+		return nil, fmt.Errorf("unable to compute an initial solution: %w", ctx.Err())
+	}
+	log.Info().Int("step-count", len(initialSolution)).Msg("initial solution found")
 
-	return initialSolutionStepCount
+	return initialSolution, nil
 }
 
 // Recursive function to evaluate a board and the possible solution(s) from it.
 func evaluateBoard(board *Board, steps []int, ctx *DeepSearchContext) []int {
-	// Print debug stats.
+	// Check if the execution must be stopped, the first board not evaluated is the one to resume from.
+	if ctx.isStopped() {
+		if ctx.stoppedPath == nil && ctx.resumePath == nil {
+			ctx.stoppedPath = steps
+		}
+		return nil
+	}
+
+	// Print debug stats and save a checkpoint if needed, unless the board to resume from is not reached yet.
+	ctx.stats.addEvaluation()
 	ctx.evaluationCounter++
 	if ctx.evaluationCounter%10_000 == 0 {
 		ctx.logStats(false)
+		if ctx.checkpointer != nil && ctx.resumePath == nil {
+			ctx.checkpointer.saveIfDue(ctx, steps)
+		}
 	}
 
+	// Check if the board is on the path to the board to resume from.
+	onResumePath := ctx.resumePath != nil
+
 	// Get the current step count.
 	currentStepCount := len(steps)
 
@@ -90,23 +177,10 @@ func evaluateBoard(board *Board, steps []int, ctx *DeepSearchContext) []int {
 		// Check if we improved the overall best solution.
 		if currentStepCount < ctx.bestSolutionStepCount {
 			ctx.bestSolutionStepCount = currentStepCount
+			ctx.bestSolution = steps
 
-			// Push the new solution to the channel.
-			ctx.solutions <- steps
-
-			// Clear the cache entries with step count greater than the new solution.
-			deletedCount := 0
-			for id, entry := range ctx.processedCache {
-				if entry.stepCount >= currentStepCount {
-					delete(ctx.processedCache, id)
-					deletedCount++
-				}
-			}
-
-			log.Debug().
-				Int("deleted", deletedCount).
-				Int("new-size", len(ctx.processedCache)).
-				Msg("cache cleaned")
+			// Report the new solution.
+			ctx.onSolution(steps)
 		}
 
 		return steps
@@ -114,56 +188,45 @@ func evaluateBoard(board *Board, steps []int, ctx *DeepSearchContext) []int {
 
 	// Check if we can still hope to improve the current best solution.
 	// Check that the number of remaining colors in the board (i.e. minimum number of steps to play) allows to improve.
-	remainingColors := board.getRemainingColors()
-	if (currentStepCount + len(remainingColors)) >= ctx.bestSolutionStepCount {
+	if (currentStepCount + board.getRemainingColorCount()) >= ctx.bestSolutionStepCount {
 		// We can't improve, just stop there.
 		ctx.prunedCounter++
+		if onResumePath {
+			// The rest of the path is pruned too, the search continues normally.
+			ctx.resumePath = nil
+		}
 		return nil
 	}
 
-	// Check if we have already processed this board configuration.
-	boardId := board.getId()
-	cacheEntry, alreadyProcessed := ctx.processedCache[boardId]
-	if alreadyProcessed {
-		ctx.cacheHitCounter++
-
-		// Check if we are improving the best solution for this board.
-		previousStepCount := cacheEntry.stepCount
-		if currentStepCount < previousStepCount {
-			ctx.cacheImprovedCounter++
-
-			// We are improving, check if there was a valid previous solution.
-			if cacheEntry.bestSolution != nil {
-				ctx.cacheMergedCounter++
-
-				// Merge the previous best solution with the current one.
-				diff := previousStepCount - currentStepCount
-				newStepCount := len(cacheEntry.bestSolution) - diff
-				newBestSolution := make([]int, newStepCount)
-				for i := 0; i < newStepCount; i++ {
-					if i < currentStepCount {
-						newBestSolution[i] = steps[i]
-					} else {
-						newBestSolution[i] = cacheEntry.bestSolution[i+diff]
-					}
-				}
-
-				// Return the merged solution.
-				return cacheEntry.bestSolution
-			}
-
-			// We are improving, but they were no valid previous solution for this board configuration.
-			// Maybe there can be one now with a smaller step count, so we continue the evaluation (i.e. no return).
-		} else {
-			// We are not improving, stop now.
-			return nil
-		}
+	// Check if we have already processed this board configuration with a lower or equal step count.
+	// The entries with a step count greater than the current best solution are useless as such boards are pruned
+	// before being looked up, they are eventually replaced in the transposition table by the more recent ones.
+	// The boards on the path to the board to resume from are being processed, they may be in a restored table.
+	if !onResumePath && !ctx.processedCache.markProcessed(board, currentStepCount) {
+		return nil
 	}
 
 	// Get the list of colors in the frontier ordered by descending area size.
-	// Return the first one (guaranteed to exist as the board is not solved).
 	colors := board.getColorsInFrontier()
 
+	// Skip the colors whose branches have been explored before the checkpoint, the order of the colors being the same.
+	if onResumePath {
+		resumeColor := ctx.resumePath[currentStepCount]
+		for i, color := range colors {
+			if color == resumeColor {
+				colors = colors[i:]
+				break
+			}
+		}
+		if colors[0] != resumeColor {
+			log.Warn().Ints("steps", steps).Int("color", resumeColor).Msg("invalid checkpoint path, the color is not in the frontier")
+			ctx.resumePath = nil
+		} else if currentStepCount == len(ctx.resumePath)-1 {
+			// The next board is the one to resume from, the search continues normally.
+			ctx.resumePath = nil
+		}
+	}
+
 	// Try all the colors in the frontier and continue the evaluation.
 	var localBestSolution []int = nil
 	for _, color := range colors {
@@ -179,13 +242,6 @@ func evaluateBoard(board *Board, steps []int, ctx *DeepSearchContext) []int {
 		// Continue the evaluation.
 		solution := evaluateBoard(boardCopy, stepsCopy, ctx)
 
-		// Update the cache.
-		boardCopyId := board.getId()
-		ctx.processedCache[boardCopyId] = &DeepSearchCacheEntry{
-			stepCount:    currentStepCount + 1,
-			bestSolution: solution,
-		}
-
 		// Check if we improved the local best solution.
 		if solution != nil {
 			// Check if the current solution is better than the best local one.
@@ -197,47 +253,52 @@ func evaluateBoard(board *Board, steps []int, ctx *DeepSearchContext) []int {
 		}
 	}
 
-	// Update the cache.
-	ctx.processedCache[boardId] = &DeepSearchCacheEntry{
-		stepCount:    currentStepCount,
-		bestSolution: localBestSolution,
-	}
-
 	return localBestSolution
 }
 
-// DeepSearchCacheEntry contains the properties of a board configuration cached entry.
-type DeepSearchCacheEntry struct {
-	// The minimum step count at which this board configuration has been evaluated.
-	stepCount int
-
-	// The best solution available from this board configuration.
-	bestSolution []int
-}
-
 // DeepSearchContext contains the properties used by the deep search implementation recursive calls.
 type DeepSearchContext struct {
 	// Debug flag to activate some logs.
 	debug bool
 
-	// Current best solution step count.
+	// Channel closed when the execution must be stopped.
+	done <-chan struct{}
+
+	// Current best solution and its step count.
+	bestSolution          []int
 	bestSolutionStepCount int
 
-	// Cache containing the already processed board configuration.
-	// The key is a string uniquely identifying a configuration see Board.getId.
-	// The value is an instance of type DeepSearchCacheEntry.
-	processedCache map[string]*DeepSearchCacheEntry
+	// Transposition table containing the already processed board configurations.
+	processedCache *TranspositionTable
+
+	// The callback function used to report the solutions found.
+	onSolution SolutionFn
+
+	// Statistics of the execution.
+	stats *SolverStats
+
+	// Checkpoints of the search state, nil if disabled.
+	checkpointer *DeepSearchCheckpointer
 
-	// The channel in which to send the solutions found.
-	solutions chan []int
+	// Steps leading to the board to resume the search from, nil once it has been reached.
+	// Steps leading to the first board not evaluated when the execution has been stopped, nil if not stopped.
+	resumePath  []int
+	stoppedPath []int
 
 	// Debug statistics.
-	evaluationCounter    int
-	solvedCounter        int
-	prunedCounter        int
-	cacheHitCounter      int
-	cacheImprovedCounter int
-	cacheMergedCounter   int
+	evaluationCounter int
+	solvedCounter     int
+	prunedCounter     int
+}
+
+// Returns whether the execution must be stopped.
+func (ctx *DeepSearchContext) isStopped() bool {
+	select {
+	case <-ctx.done:
+		return true
+	default:
+		return false
+	}
 }
 
 // Log the debug statistics.
@@ -253,10 +314,10 @@ func (ctx *DeepSearchContext) logStats(finished bool) {
 			Int("evaluation", ctx.evaluationCounter).
 			Int("solved", ctx.solvedCounter).
 			Int("pruned", ctx.prunedCounter).
-			Int("cache-size", len(ctx.processedCache)).
-			Int("cache-hit", ctx.cacheHitCounter).
-			Int("cache-improvement", ctx.cacheImprovedCounter).
-			Int("cache-merge", ctx.cacheMergedCounter).
+			Int64("cache-size", ctx.processedCache.entryCounter.Load()).
+			Int64("cache-hit", ctx.processedCache.hitCounter.Load()).
+			Int64("cache-collision", ctx.processedCache.collisionCounter.Load()).
+			Int64("cache-eviction", ctx.processedCache.evictionCounter.Load()).
 			Msg(msg)
 	}
 }
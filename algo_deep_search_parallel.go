@@ -0,0 +1,366 @@
+package main
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Minimum number of steps that must remain to be played before reaching the current best solution for a branch
+// to be shared with the idle workers. Smaller branches are evaluated faster than they would be shared.
+const minSharedBranchDepth = 4
+
+// Implementation exploring the space of possibilities with a deep tree search to identify the optimal solution.
+// The search tree is split across multiple worker Goroutines with a work-stealing scheduler: each worker has its own
+// deque of branches, it shares branches in its deque when some workers are idle and evaluates them last-in first-out
+// once its current branch is evaluated, while the idle workers steal the oldest ones, i.e. the largest ones, from the
+// deques of the other workers.
+func parallelDeepSearch(execCtx context.Context, board *Board, onSolution SolutionFn, config *SolverConfig) ([]int, error) {
+	// First compute a "good" solution to have an initial step count that will be used to prune the graph search.
+	initialSolution, err := computeInitialSolution(execCtx, board, onSolution, config.knownSolution)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the context shared by all the workers.
+	workerCount := config.workers
+	if workerCount <= 0 {
+		workerCount = runtime.NumCPU()
+	}
+	ctx := &ParallelDeepSearchContext{
+		debug:          config.debug,
+		done:           execCtx.Done(),
+		processedCache: newTranspositionTable(getTranspositionTableSizeMb(config.cacheMb, board), config.cacheVerify),
+		bestSolution:   initialSolution,
+		onSolution:     onSolution,
+		stats:          config.stats,
+		deques:         make([]*WorkStealingDeque, workerCount),
+	}
+	for i := range ctx.deques {
+		ctx.deques[i] = &WorkStealingDeque{}
+	}
+	ctx.idleCond = sync.NewCond(&ctx.idleMutex)
+	ctx.bestSolutionStepCount.Store(int64(len(initialSolution)))
+
+	// The root board is the first branch to evaluate, in the deque of the first worker.
+	ctx.pushTask(0, &ParallelDeepSearchTask{board: board, steps: []int{}})
+
+	// Wake up the idle workers when the execution must be stopped.
+	finished := make(chan void)
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.done:
+			ctx.idleMutex.Lock()
+			ctx.idleCond.Broadcast()
+			ctx.idleMutex.Unlock()
+		case <-finished:
+		}
+	}()
+
+	// Launch the workers and wait for them to evaluate all the branches.
+	log.Debug().Int("workers", workerCount).Msg("starting the parallel deep search")
+	var waitGroup sync.WaitGroup
+	for i := 0; i < workerCount; i++ {
+		waitGroup.Add(1)
+		id := i
+		go func() {
+			defer waitGroup.Done()
+			ctx.runWorker(id)
+		}()
+	}
+	waitGroup.Wait()
+
+	// Print debug stats.
+	ctx.logStats(true)
+
+	// Check if the search tree has been fully explored, the best solution is then optimal unless a hash collision of
+	// the transposition table pruned a branch, which is only excluded when the entries are verified.
+	if execCtx.Err() == nil && config.cacheVerify {
+		ctx.stats.setOptimalProven()
+	}
+
+	return ctx.getBestSolution(), execCtx.Err()
+}
+
+// ParallelDeepSearchTask is a branch of the search tree to be evaluated by a worker.
+type ParallelDeepSearchTask struct {
+	// The board configuration at the root of the branch.
+	board *Board
+
+	// The steps played to reach this board configuration.
+	steps []int
+}
+
+// WorkStealingDeque is the deque of the branches of a worker, the worker pushes and pops them at the bottom while the
+// other workers steal them from the top. It's safe for concurrent use by multiple Goroutines.
+type WorkStealingDeque struct {
+	mutex sync.Mutex
+	tasks []*ParallelDeepSearchTask
+}
+
+// Add a branch at the bottom of the deque.
+func (deque *WorkStealingDeque) pushBottom(task *ParallelDeepSearchTask) {
+	deque.mutex.Lock()
+	defer deque.mutex.Unlock()
+	deque.tasks = append(deque.tasks, task)
+}
+
+// Remove the branch at the bottom of the deque, i.e. the newest one, and return it. Returns nil if it's empty.
+func (deque *WorkStealingDeque) popBottom() *ParallelDeepSearchTask {
+	deque.mutex.Lock()
+	defer deque.mutex.Unlock()
+	if len(deque.tasks) == 0 {
+		return nil
+	}
+	task := deque.tasks[len(deque.tasks)-1]
+	deque.tasks[len(deque.tasks)-1] = nil
+	deque.tasks = deque.tasks[:len(deque.tasks)-1]
+	return task
+}
+
+// Remove the branch at the top of the deque, i.e. the oldest one, and return it. Returns nil if it's empty.
+func (deque *WorkStealingDeque) steal() *ParallelDeepSearchTask {
+	deque.mutex.Lock()
+	defer deque.mutex.Unlock()
+	if len(deque.tasks) == 0 {
+		return nil
+	}
+	task := deque.tasks[0]
+	deque.tasks[0] = nil
+	deque.tasks = deque.tasks[1:]
+	return task
+}
+
+// ParallelDeepSearchContext contains the properties shared by the workers of the parallel deep search implementation.
+type ParallelDeepSearchContext struct {
+	// Debug flag to activate some logs.
+	debug bool
+
+	// Channel closed when the execution must be stopped.
+	done <-chan struct{}
+
+	// Current best solution step count, it can be read without holding the best solution lock.
+	bestSolutionStepCount atomic.Int64
+
+	// Current best solution, protected by its lock.
+	bestSolutionMutex sync.Mutex
+	bestSolution      []int
+
+	// The callback function used to report the solutions found.
+	onSolution SolutionFn
+
+	// Statistics of the execution.
+	stats *SolverStats
+
+	// Deques of the branches waiting to be evaluated, one per worker, indexed by the worker ID.
+	deques []*WorkStealingDeque
+
+	// Number of branches waiting to be evaluated or being evaluated.
+	pendingTaskCount atomic.Int64
+
+	// Number of workers trying to steal a branch to evaluate.
+	idleWorkerCount atomic.Int32
+
+	// Condition on which the idle workers wait for a branch to be shared, for the last branch to be evaluated or for
+	// the execution to be stopped, and the number of branches shared so far, protected by the idle lock.
+	idleMutex       sync.Mutex
+	idleCond        *sync.Cond
+	sharedTaskCount int64
+
+	// Transposition table containing the already processed board configurations.
+	processedCache *TranspositionTable
+
+	// Debug statistics.
+	evaluationCounter atomic.Int64
+	solvedCounter     atomic.Int64
+	prunedCounter     atomic.Int64
+	sharedCounter     atomic.Int64
+}
+
+// Returns whether the execution must be stopped.
+func (ctx *ParallelDeepSearchContext) isStopped() bool {
+	select {
+	case <-ctx.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Add a branch to the deque of a worker and wake up the idle workers so that they can steal it.
+func (ctx *ParallelDeepSearchContext) pushTask(worker int, task *ParallelDeepSearchTask) {
+	ctx.pendingTaskCount.Add(1)
+	ctx.deques[worker].pushBottom(task)
+
+	ctx.idleMutex.Lock()
+	defer ctx.idleMutex.Unlock()
+	ctx.sharedTaskCount++
+	ctx.idleCond.Broadcast()
+}
+
+// Steal a branch from the deques of the other workers, waiting for one to be shared if they are all empty.
+// Returns nil if all the branches have been evaluated or if the execution must be stopped.
+func (ctx *ParallelDeepSearchContext) stealTask(worker int) *ParallelDeepSearchTask {
+	ctx.idleWorkerCount.Add(1)
+	defer ctx.idleWorkerCount.Add(-1)
+
+	for {
+		// Get the number of branches shared before trying to steal one, to detect the ones shared in the meantime.
+		ctx.idleMutex.Lock()
+		sharedTaskCount := ctx.sharedTaskCount
+		ctx.idleMutex.Unlock()
+
+		// Try the other workers in turn, starting with the next one.
+		for i := 1; i < len(ctx.deques); i++ {
+			if task := ctx.deques[(worker+i)%len(ctx.deques)].steal(); task != nil {
+				return task
+			}
+		}
+
+		// Wait for another worker to share a branch or to finish its evaluation.
+		ctx.idleMutex.Lock()
+		if ctx.pendingTaskCount.Load() == 0 || ctx.isStopped() {
+			ctx.idleMutex.Unlock()
+			return nil
+		}
+		if sharedTaskCount == ctx.sharedTaskCount {
+			ctx.idleCond.Wait()
+		}
+		ctx.idleMutex.Unlock()
+	}
+}
+
+// Mark a branch as evaluated and wake up all the idle workers if it was the last one.
+func (ctx *ParallelDeepSearchContext) completeTask() {
+	if ctx.pendingTaskCount.Add(-1) == 0 {
+		ctx.idleMutex.Lock()
+		defer ctx.idleMutex.Unlock()
+		ctx.idleCond.Broadcast()
+	}
+}
+
+// Main function of a worker, evaluating the branches of its deque, or stolen from the other workers when it's empty,
+// until there is no more to evaluate.
+func (ctx *ParallelDeepSearchContext) runWorker(worker int) {
+	for {
+		task := ctx.deques[worker].popBottom()
+		if task == nil {
+			task = ctx.stealTask(worker)
+		}
+		if task == nil || ctx.isStopped() {
+			return
+		}
+
+		ctx.evaluateBoard(worker, task.board, task.steps)
+		ctx.completeTask()
+	}
+}
+
+// Returns the current best solution.
+func (ctx *ParallelDeepSearchContext) getBestSolution() []int {
+	ctx.bestSolutionMutex.Lock()
+	defer ctx.bestSolutionMutex.Unlock()
+
+	return ctx.bestSolution
+}
+
+// Check if a solution improves the overall best one and, if yes, report it.
+func (ctx *ParallelDeepSearchContext) processSolution(steps []int) {
+	ctx.bestSolutionMutex.Lock()
+	improved := len(steps) < len(ctx.bestSolution)
+	if improved {
+		ctx.bestSolution = steps
+		ctx.bestSolutionStepCount.Store(int64(len(steps)))
+	}
+	ctx.bestSolutionMutex.Unlock()
+
+	if improved {
+		// Report the new solution.
+		ctx.onSolution(steps)
+	}
+}
+
+// Recursive function to evaluate a board and the possible solution(s) from it.
+// The branches shared with the idle workers are pushed to the deque of the worker.
+func (ctx *ParallelDeepSearchContext) evaluateBoard(worker int, board *Board, steps []int) {
+	// Check if the execution must be stopped.
+	if ctx.isStopped() {
+		return
+	}
+
+	// Print debug stats.
+	ctx.stats.addEvaluation()
+	if ctx.evaluationCounter.Add(1)%100_000 == 0 {
+		ctx.logStats(false)
+	}
+
+	// Get the current step count.
+	currentStepCount := len(steps)
+
+	// Check if the board is solved.
+	if board.isSolved() {
+		ctx.solvedCounter.Add(1)
+		ctx.processSolution(steps)
+		return
+	}
+
+	// Check if we can still hope to improve the current best solution.
+	// Check that the number of remaining colors in the board (i.e. minimum number of steps to play) allows to improve.
+	bestSolutionStepCount := int(ctx.bestSolutionStepCount.Load())
+	if (currentStepCount + board.getRemainingColorCount()) >= bestSolutionStepCount {
+		// We can't improve, just stop there.
+		ctx.prunedCounter.Add(1)
+		return
+	}
+
+	// Check if we have already processed this board configuration with a lower or equal step count.
+	if !ctx.processedCache.markProcessed(board, currentStepCount) {
+		return
+	}
+
+	// Try all the colors in the frontier and continue the evaluation.
+	colors := board.getColorsInFrontier()
+	for _, color := range colors {
+		// Clone and update the board.
+		boardCopy := board.clone()
+		boardCopy.playStep(color)
+
+		// Copy the steps and append the current color.
+		stepsCopy := make([]int, currentStepCount+1)
+		copy(stepsCopy, steps)
+		stepsCopy[len(stepsCopy)-1] = color
+
+		// Share the branch if some workers are idle and if it's large enough, otherwise continue the evaluation.
+		if ctx.idleWorkerCount.Load() > 0 && (bestSolutionStepCount-currentStepCount) > minSharedBranchDepth {
+			ctx.sharedCounter.Add(1)
+			ctx.pushTask(worker, &ParallelDeepSearchTask{board: boardCopy, steps: stepsCopy})
+		} else {
+			ctx.evaluateBoard(worker, boardCopy, stepsCopy)
+		}
+	}
+}
+
+// Log the debug statistics.
+func (ctx *ParallelDeepSearchContext) logStats(finished bool) {
+	if ctx.debug {
+		msg := "progress"
+		if finished {
+			msg = "finished"
+		}
+
+		log.Debug().
+			Int64("best", ctx.bestSolutionStepCount.Load()).
+			Int64("evaluation", ctx.evaluationCounter.Load()).
+			Int64("solved", ctx.solvedCounter.Load()).
+			Int64("pruned", ctx.prunedCounter.Load()).
+			Int64("cache-size", ctx.processedCache.entryCounter.Load()).
+			Int64("cache-hit", ctx.processedCache.hitCounter.Load()).
+			Int64("cache-collision", ctx.processedCache.collisionCounter.Load()).
+			Int64("cache-eviction", ctx.processedCache.evictionCounter.Load()).
+			Int64("shared", ctx.sharedCounter.Load()).
+			Msg(msg)
+	}
+}
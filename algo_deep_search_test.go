@@ -1,7 +1,68 @@
 package main
 
-import "testing"
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeepSearchOptimalProven(t *testing.T) {
+	// The solution is only proven optimal if the transposition table entries are verified.
+	for _, cacheVerify := range []bool{false, true} {
+		stats := &SolverStats{}
+		testImplementation(t, deepSearch, &SolverConfig{cacheVerify: cacheVerify, stats: stats}, "samples/12_12_4-1.csv")
+		if stats.isOptimalProven() != cacheVerify {
+			t.Fatalf("unexpected optimal proof, cache-verify=%v", cacheVerify)
+		}
+	}
+}
+
+func TestDeepSearchCheckpointResume(t *testing.T) {
+	board, err := generateBoard("random", 12, 12, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	onSolution := func(solution []int) {}
+	optimalSolution, err := deepSearch(context.Background(), board.clone(), onSolution, &SolverConfig{cacheMb: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Chain short executions resuming from the checkpoint until the search is completed.
+	for _, checkpointCache := range []bool{false, true} {
+		checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+		for run := 1; ; run++ {
+			if run > 1000 {
+				t.Fatalf("the search should have been completed, cache=%v", checkpointCache)
+			}
+
+			stats := &SolverStats{}
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			solution, err := deepSearch(ctx, board.clone(), onSolution, &SolverConfig{
+				cacheMb:         16,
+				cacheVerify:     true,
+				stats:           stats,
+				checkpointFile:  checkpointFile,
+				checkpointCache: checkpointCache,
+				resume:          true,
+			})
+			cancel()
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatal(err)
+			}
+			if stats.isOptimalProven() {
+				if len(solution) != len(optimalSolution) {
+					t.Fatalf("invalid solution after %d runs, cache=%v, expected=%d, actual=%d", run, checkpointCache, len(optimalSolution), len(solution))
+				}
+				t.Logf("search completed after %d runs, cache=%v", run, checkpointCache)
+				break
+			}
+		}
+	}
+}
 
 func BenchmarkDeepSearch(b *testing.B) {
-	benchmarkImplementation(b, deepSearch, "samples/30_30_3-1.csv")
+	benchmarkImplementation(b, deepSearch, &SolverConfig{}, "samples/30_30_3-1.csv")
 }
@@ -1,30 +1,27 @@
 package main
 
 import (
+	"context"
 	"math/rand"
 )
 
 // Dummy implementation randomly selecting a color in the frontier at each step.
-func dummy(board *Board, solutions chan []int, done chan void, debug bool) ([]int, error) {
-	return linearImpl(board, solutions, done, randomPickColor, debug)
+func dummy(ctx context.Context, board *Board, onSolution SolutionFn, config *SolverConfig) ([]int, error) {
+	return linearImpl(ctx, board, onSolution, randomPickColor, config.boardRenderer)
 }
 
-// Returns a randomly picked color from the frontier.
-func randomPickColor(board *Board) int {
-	// Get the set of available colors in the frontier.
-	colors := make(map[int]void)
-	for cellId := range board.frontierCells {
-		color := board.cells[cellId]
-		colors[color] = void{}
+// Returns a color picker function similar to randomPickColor, using the specified random number generator.
+func newRandomPickColor(random *rand.Rand) ColorPickerFn {
+	return func(board *Board) int {
+		choices := board.getColorsInFrontier()
+		return choices[random.Intn(len(choices))]
 	}
+}
 
-	// Get the frontier colors as a slice.
-	choices := make([]int, len(colors))
-	i := 0
-	for color := range colors {
-		choices[i] = color
-		i++
-	}
+// Returns a randomly picked color from the frontier.
+func randomPickColor(board *Board) int {
+	// Get the list of available colors in the frontier.
+	choices := board.getColorsInFrontier()
 
 	// Pick one color randomly.
 	choiceIdx := rand.Intn(len(choices))
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+	"math"
+)
+
+// Implementation using the IDA* algorithm on the region graph of the board to find the optimal solution.
+// Successive depth-first searches are executed with an increasing threshold on the minimum total step count of the
+// evaluated boards, computed with the same admissible heuristic as the A* implementation. Thus, the first solution
+// found is optimal while the memory usage stays bounded by the size of the transposition cache of one iteration.
+func idaStar(execCtx context.Context, board *Board, onSolution SolutionFn, config *SolverConfig) ([]int, error) {
+	// First compute a "good" solution to have an initial step count that will be used to prune the graph search.
+	initialSolution, err := computeInitialSolution(execCtx, board, onSolution, config.knownSolution)
+	if err != nil {
+		return nil, err
+	}
+
+	graphBoard := NewGraphBoard(newRegionGraph(board))
+	ctx := &IdaStarContext{
+		debug: config.debug,
+		done:  execCtx.Done(),
+		stats: config.stats,
+	}
+
+	// Increase the threshold until a solution is found or until it reaches the initial solution step count.
+	for threshold := computeMinStepCount(graphBoard); threshold < len(initialSolution); threshold = ctx.nextThreshold {
+		log.Debug().Int("threshold", threshold).Msg("starting a new iteration")
+
+		// Evaluate the board with the current threshold.
+		ctx.threshold = threshold
+		ctx.nextThreshold = math.MaxInt
+		ctx.steps = ctx.steps[:0]
+		ctx.processedCache = make(map[string]int)
+		found := ctx.evaluateBoard(graphBoard)
+
+		// Check if the execution has been stopped.
+		if err := execCtx.Err(); err != nil {
+			return initialSolution, err
+		}
+
+		// Check if a solution has been found, it is then optimal.
+		if found {
+			solution := make([]int, len(ctx.steps))
+			copy(solution, ctx.steps)
+			onSolution(solution)
+			log.Info().Int("step-count", len(solution)).Msg("optimal solution found")
+			config.stats.setOptimalProven()
+			return solution, nil
+		}
+	}
+
+	// No solution shorter than the initial one exists, it is thus optimal.
+	log.Info().Int("step-count", len(initialSolution)).Msg("optimal solution found")
+	config.stats.setOptimalProven()
+	return initialSolution, nil
+}
+
+// IdaStarContext contains the properties used by the IDA* implementation recursive calls.
+type IdaStarContext struct {
+	// Debug flag to activate some logs.
+	debug bool
+
+	// Channel closed when the execution must be stopped.
+	done <-chan struct{}
+
+	// Maximum minimum total step count of the boards evaluated during the current iteration.
+	threshold int
+
+	// Smallest minimum total step count exceeding the threshold, it will be the threshold of the next iteration.
+	nextThreshold int
+
+	// Steps played to reach the board currently evaluated.
+	steps []int
+
+	// Cache containing the already processed board configuration during the current iteration.
+	// The key is a string uniquely identifying a configuration see GraphBoard.getId.
+	// The value is the minimum step count at which this board configuration has been evaluated.
+	processedCache map[string]int
+
+	// Statistics of the execution.
+	stats *SolverStats
+
+	// Debug statistics.
+	evaluationCounter int
+}
+
+// Recursive function to evaluate a board, returns true if a solution has been found from it.
+// In this case, the solution is available in the context steps.
+func (ctx *IdaStarContext) evaluateBoard(board *GraphBoard) bool {
+	// Check if the execution must be stopped.
+	select {
+	case <-ctx.done:
+		return false
+	default:
+	}
+
+	// Print debug stats.
+	ctx.stats.addEvaluation()
+	ctx.evaluationCounter++
+	if ctx.debug && ctx.evaluationCounter%100_000 == 0 {
+		log.Debug().
+			Int("threshold", ctx.threshold).
+			Int("evaluation", ctx.evaluationCounter).
+			Int("cache-size", len(ctx.processedCache)).
+			Msg("progress")
+	}
+
+	// Check if the minimum total step count of the board exceeds the threshold.
+	stepCount := len(ctx.steps)
+	minStepCount := stepCount + computeMinStepCount(board)
+	if minStepCount > ctx.threshold {
+		if minStepCount < ctx.nextThreshold {
+			ctx.nextThreshold = minStepCount
+		}
+		return false
+	}
+
+	// Check if the board is solved.
+	if board.isSolved() {
+		return true
+	}
+
+	// Check if we have already processed this board configuration with a lower or equal step count.
+	id := board.getId()
+	previousStepCount, alreadyProcessed := ctx.processedCache[id]
+	if alreadyProcessed && previousStepCount <= stepCount {
+		return false
+	}
+	ctx.processedCache[id] = stepCount
+
+	// Try all the colors in the frontier and continue the evaluation.
+	for _, color := range board.getColorsInFrontier() {
+		// Clone and update the board.
+		boardCopy := board.clone()
+		boardCopy.playStep(color)
+
+		// Continue the evaluation.
+		ctx.steps = append(ctx.steps, color)
+		if ctx.evaluateBoard(boardCopy) {
+			return true
+		}
+		ctx.steps = ctx.steps[:stepCount]
+	}
+
+	return false
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestIdaStar(t *testing.T) {
+	for _, testCase := range []struct {
+		inputFile string
+		optimum   int
+	}{
+		{"samples/12_12_4-1.csv", 12},
+		{"samples/12_12_5-1.csv", 14},
+	} {
+		solution := testImplementation(t, idaStar, &SolverConfig{}, testCase.inputFile)
+		if len(solution) != testCase.optimum {
+			t.Fatalf("the solution of %s is not optimal, expected=%d, actual=%d", testCase.inputFile, testCase.optimum, len(solution))
+		}
+	}
+}
+
+func BenchmarkIdaStar(b *testing.B) {
+	benchmarkImplementation(b, idaStar, &SolverConfig{}, "samples/15_15_4-1.csv")
+}
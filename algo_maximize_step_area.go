@@ -1,8 +1,13 @@
 package main
 
+import (
+	"context"
+	"math/rand"
+)
+
 // Implementation selecting the color that maximizes the converted area for each step.
-func maximizeStepArea(board *Board, solutions chan []int, done chan void, debug bool) ([]int, error) {
-	return linearImpl(board, solutions, done, pickColorWithLargestArea, debug)
+func maximizeStepArea(ctx context.Context, board *Board, onSolution SolutionFn, config *SolverConfig) ([]int, error) {
+	return linearImpl(ctx, board, onSolution, pickColorWithLargestArea, config.boardRenderer)
 }
 
 // Returns the color from the frontier with the largest area.
@@ -13,18 +18,32 @@ func pickColorWithLargestArea(board *Board) int {
 }
 
 // Implementation selecting the color that maximizes the converted area for N steps in the tree of configurations.
-func maximizeStepAreaDeep(board *Board, solutions chan []int, done chan void, debug bool) ([]int, error) {
-	return linearImpl(board, solutions, done, pickColorWithLargestAreaDeep, debug)
+func maximizeStepAreaDeep(ctx context.Context, board *Board, onSolution SolutionFn, config *SolverConfig) ([]int, error) {
+	return linearImpl(ctx, board, onSolution, pickColorWithLargestAreaDeep, config.boardRenderer)
 }
 
 // Returns the color from the frontier with the largest area for N steps in the tree of configurations.
 func pickColorWithLargestAreaDeep(board *Board) int {
-	depth := 3 // 3 is the best trade-off between performance and accuracy
-	color, _ := doPickColorWithLargestAreaDeep(board, depth)
+	color, _ := doPickColorWithLargestAreaDeep(board, pickColorWithLargestAreaDeepDepth, nil)
 	return color
 }
 
-func doPickColorWithLargestAreaDeep(board *Board, depth int) (int, *Board) {
+// Number of steps evaluated by pickColorWithLargestAreaDeep, 3 is the best trade-off between performance and accuracy.
+const pickColorWithLargestAreaDeepDepth = 3
+
+// Returns a color picker function similar to pickColorWithLargestAreaDeep, except that the colors with the same
+// largest area are picked randomly using the specified seed.
+func newRandomizedPickColorWithLargestAreaDeep(seed int64) ColorPickerFn {
+	random := rand.New(rand.NewSource(seed))
+	return func(board *Board) int {
+		color, _ := doPickColorWithLargestAreaDeep(board, pickColorWithLargestAreaDeepDepth, random)
+		return color
+	}
+}
+
+// Recursive function returning the color maximizing the completed area for N steps along with the resulting board.
+// If a random number generator is provided, the colors are evaluated in a random order to break the ties randomly.
+func doPickColorWithLargestAreaDeep(board *Board, depth int, random *rand.Rand) (int, *Board) {
 	// Check if the board is solved.
 	if board.isSolved() {
 		return -1, board
@@ -38,6 +57,11 @@ func doPickColorWithLargestAreaDeep(board *Board, depth int) (int, *Board) {
 
 	// Get the list of colors in the frontier ordered by descending area size.
 	colors := board.getColorsInFrontier()
+	if random != nil {
+		random.Shuffle(len(colors), func(i, j int) {
+			colors[i], colors[j] = colors[j], colors[i]
+		})
+	}
 
 	// Try all the colors in the frontier.
 	resultColor := -1
@@ -49,10 +73,10 @@ func doPickColorWithLargestAreaDeep(board *Board, depth int) (int, *Board) {
 		boardCopy.playStep(color)
 
 		// Continue the evaluation.
-		_, bestBoard := doPickColorWithLargestAreaDeep(boardCopy, depth-1)
+		_, bestBoard := doPickColorWithLargestAreaDeep(boardCopy, depth-1, random)
 
 		// Check if we improved the local best solution.
-		if resultBoard == nil || len(bestBoard.completedCells) > len(resultBoard.completedCells) {
+		if resultBoard == nil || bestBoard.completedCount > resultBoard.completedCount {
 			resultColor = color
 			resultBoard = bestBoard
 		}
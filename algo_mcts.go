@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Exploration constant of the UCT formula used to select the node to explore, the rewards being in (0, 1].
+const mctsExplorationConstant = 0.1
+
+// Probability to play the color with the largest area during a rollout, instead of a random color.
+const mctsGreedyRolloutProbability = 0.8
+
+// Implementation using a Monte Carlo Tree Search to find solutions: at each iteration, the most promising node of the
+// tree of configurations is selected using the UCT formula, expanded with one of its colors and the resulting board is
+// solved with a randomized greedy rollout. The shorter the rollout solution, the higher the reward.
+// It's an anytime algorithm running until the execution is stopped or the tree is fully explored.
+func monteCarloTreeSearch(ctx context.Context, board *Board, onSolution SolutionFn, config *SolverConfig) ([]int, error) {
+	// First compute a "good" solution to have an initial step count used to compute the rewards and to prune the tree.
+	initialSolution, err := computeInitialSolution(ctx, board, onSolution, config.knownSolution)
+	if err != nil {
+		return nil, err
+	}
+
+	seed := config.seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	random := rand.New(rand.NewSource(seed))
+	mcts := &MonteCarloTreeSearch{
+		rootBoard:       board,
+		root:            &MctsNode{color: -1, untriedColors: board.getColorsInFrontier()},
+		bestSolution:    initialSolution,
+		onSolution:      onSolution,
+		stats:           config.stats,
+		random:          random,
+		randomPickColor: newRandomPickColor(random),
+	}
+
+	// Iterate until the execution is stopped or the tree is fully explored.
+	for !mcts.root.exhausted {
+		if err := ctx.Err(); err != nil {
+			return mcts.bestSolution, err
+		}
+
+		mcts.iterate()
+
+		// Print debug stats.
+		if config.debug && mcts.iterationCounter%10_000 == 0 {
+			log.Debug().
+				Int("best", len(mcts.bestSolution)).
+				Int("iteration", mcts.iterationCounter).
+				Int("nodes", mcts.nodeCounter).
+				Float64("root-reward", mcts.root.totalReward/float64(mcts.root.visitCount)).
+				Msg("progress")
+		}
+	}
+
+	log.Info().Int("step-count", len(mcts.bestSolution)).Msg("optimal solution found")
+	config.stats.setOptimalProven()
+	return mcts.bestSolution, nil
+}
+
+// MctsNode is a node of the Monte Carlo Tree Search, i.e. a board configuration.
+// The boards are not stored in the nodes to limit the memory usage, they are computed again from the root board.
+type MctsNode struct {
+	// The color played to reach this node from its parent, -1 for the root.
+	color int
+
+	// The parent node, nil for the root.
+	parent *MctsNode
+
+	// The child nodes already expanded.
+	children []*MctsNode
+
+	// The colors of the frontier not yet expanded as child nodes.
+	untriedColors []int
+
+	// Number of iterations that went through this node and the sum of their rewards.
+	visitCount  int
+	totalReward float64
+
+	// Whether this node can't lead to a better solution than the best one, or has been fully explored.
+	exhausted bool
+}
+
+// MonteCarloTreeSearch contains the status of the Monte Carlo Tree Search implementation.
+type MonteCarloTreeSearch struct {
+	// The initial board and the root node of the tree.
+	rootBoard *Board
+	root      *MctsNode
+
+	// Current best solution.
+	bestSolution []int
+
+	// The callback function used to report the solutions found.
+	onSolution SolutionFn
+
+	// Statistics of the execution.
+	stats *SolverStats
+
+	// The random number generator used by the rollouts and the random color picker using it.
+	random          *rand.Rand
+	randomPickColor ColorPickerFn
+
+	// Debug statistics.
+	iterationCounter int
+	nodeCounter      int
+}
+
+// Execute an iteration of the search: selection, expansion, rollout and backpropagation.
+func (mcts *MonteCarloTreeSearch) iterate() {
+	mcts.iterationCounter++
+
+	// Selection: go down the tree, selecting the best child using the UCT formula, until reaching a node having some
+	// colors not yet expanded.
+	board := mcts.rootBoard.clone()
+	var steps []int
+	node := mcts.root
+	for len(node.untriedColors) == 0 && len(node.children) > 0 {
+		node = node.selectChild()
+		board.playStep(node.color)
+		steps = append(steps, node.color)
+	}
+
+	// Expansion: create a child node for the first color not yet expanded.
+	if len(node.untriedColors) > 0 {
+		color := node.untriedColors[0]
+		node.untriedColors = node.untriedColors[1:]
+		board.playStep(color)
+		steps = append(steps, color)
+
+		child := &MctsNode{
+			color:         color,
+			parent:        node,
+			untriedColors: board.getColorsInFrontier(),
+		}
+		node.children = append(node.children, child)
+		node = child
+		mcts.stats.addEvaluation()
+		mcts.nodeCounter++
+	}
+
+	// Check if the node can still lead to a better solution, or if it's a solved board.
+	if board.isSolved() || len(steps)+board.getRemainingColorCount() >= len(mcts.bestSolution) {
+		node.untriedColors = nil
+		node.markExhausted()
+	}
+
+	// Rollout: solve the board picking either the color with the largest area or a random one.
+	for !board.isSolved() {
+		var color int
+		if mcts.random.Float64() < mctsGreedyRolloutProbability {
+			color = pickColorWithLargestArea(board)
+		} else {
+			color = mcts.randomPickColor(board)
+		}
+		board.playStep(color)
+		steps = append(steps, color)
+	}
+
+	// Check if we improved the best solution.
+	if len(steps) < len(mcts.bestSolution) {
+		mcts.bestSolution = steps
+		mcts.onSolution(steps)
+	}
+
+	// Backpropagation: the reward is in (0, 1], 1 meaning that the solution is as good as the best one.
+	reward := float64(len(mcts.bestSolution)) / float64(len(steps))
+	for ; node != nil; node = node.parent {
+		node.visitCount++
+		node.totalReward += reward
+	}
+}
+
+// Returns the child node maximizing the UCT formula, among the ones not exhausted.
+func (node *MctsNode) selectChild() *MctsNode {
+	var bestChild *MctsNode = nil
+	bestValue := math.Inf(-1)
+	logVisitCount := math.Log(float64(node.visitCount))
+	for _, child := range node.children {
+		if child.exhausted {
+			continue
+		}
+
+		value := child.totalReward/float64(child.visitCount) +
+			mctsExplorationConstant*math.Sqrt(logVisitCount/float64(child.visitCount))
+		if value > bestValue {
+			bestChild = child
+			bestValue = value
+		}
+	}
+	return bestChild
+}
+
+// Mark a node as exhausted, along with its ancestors that have no more node to explore.
+func (node *MctsNode) markExhausted() {
+	for ; node != nil; node = node.parent {
+		if len(node.untriedColors) > 0 {
+			return
+		}
+		for _, child := range node.children {
+			if !child.exhausted {
+				return
+			}
+		}
+		node.exhausted = true
+	}
+}
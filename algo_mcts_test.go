@@ -0,0 +1,32 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMonteCarloTreeSearch(t *testing.T) {
+	for _, testCase := range []struct {
+		inputFile string
+		optimum   int
+	}{
+		{"samples/12_12_4-1.csv", 12},
+		{"samples/12_12_5-1.csv", 14},
+	} {
+		// The tree is fully explored on these small boards, the solution is then optimal.
+		solution := testImplementation(t, monteCarloTreeSearch, &SolverConfig{seed: 1}, testCase.inputFile)
+		if len(solution) != testCase.optimum {
+			t.Fatalf("the solution of %s is not optimal, expected=%d, actual=%d", testCase.inputFile, testCase.optimum, len(solution))
+		}
+
+		// The execution is reproducible with the same seed.
+		other := testImplementation(t, monteCarloTreeSearch, &SolverConfig{seed: 1}, testCase.inputFile)
+		if !reflect.DeepEqual(solution, other) {
+			t.Fatalf("the solutions of %s differ with the same seed: %v, %v", testCase.inputFile, solution, other)
+		}
+	}
+}
+
+func BenchmarkMonteCarloTreeSearch(b *testing.B) {
+	benchmarkImplementation(b, monteCarloTreeSearch, &SolverConfig{}, "samples/12_12_4-1.csv")
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"github.com/rs/zerolog/log"
+)
+
+// Implementation improving an initial solution by searching for shorter completions of its prefixes.
+// Starting from an initial solution of length L, the board reached after playing its first L-2 steps is searched
+// exhaustively for a completion making the solution shorter. If none is found, the same is done with the board
+// reached after L-3 steps, and so on upward until reaching the root of the tree. Each time a shorter solution is
+// found, it becomes the new one to improve and the search starts again from its last levels.
+// As the search window of the root is the whole tree, the solution is proven optimal when it's reached.
+func suffixImprove(execCtx context.Context, board *Board, onSolution SolutionFn, config *SolverConfig) ([]int, error) {
+	// Compute the initial solution to improve.
+	solution, err := computeInitialSolution(execCtx, board, onSolution, config.knownSolution)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := &SuffixImproveContext{
+		debug:          config.debug,
+		done:           execCtx.Done(),
+		processedCache: newTranspositionTable(getTranspositionTableSizeMb(config.cacheMb, board), config.cacheVerify),
+		stats:          config.stats,
+	}
+
+	// Search the windows from the last levels of the solution upward.
+	for level := len(solution) - 2; level >= 0; {
+		// Compute the board at the start of the window.
+		levelBoard := board.clone()
+		for _, color := range solution[:level] {
+			levelBoard.playStep(color)
+		}
+
+		// Search for a shorter completion.
+		log.Debug().Int("level", level).Int("window", len(solution)-level-1).Msg("searching window")
+		ctx.maxStepCount = len(solution) - 1
+		ctx.steps = append(ctx.steps[:0], solution[:level]...)
+		found := ctx.searchCompletion(levelBoard)
+
+		// Check if the execution has been stopped.
+		if err := execCtx.Err(); err != nil {
+			return solution, err
+		}
+
+		if !found {
+			// No shorter completion from this level, go upward.
+			level--
+			continue
+		}
+
+		// Report the improved solution and start again from its last levels.
+		// The transposition table is cleared as the search stopped before fully exploring the boards being evaluated.
+		solution = make([]int, len(ctx.steps))
+		copy(solution, ctx.steps)
+		onSolution(solution)
+		ctx.processedCache.reset()
+		level = len(solution) - 2
+	}
+
+	// The root window has been fully searched without finding a shorter solution, it's optimal unless a hash collision
+	// of the transposition table pruned a branch, which is only excluded when the entries are verified.
+	if config.cacheVerify {
+		log.Info().Int("step-count", len(solution)).Msg("optimal solution found")
+		config.stats.setOptimalProven()
+	}
+	return solution, nil
+}
+
+// SuffixImproveContext contains the properties used by the suffix improvement implementation recursive calls.
+type SuffixImproveContext struct {
+	// Debug flag to activate some logs.
+	debug bool
+
+	// Channel closed when the execution must be stopped.
+	done <-chan struct{}
+
+	// Maximum step count of the solutions searched.
+	maxStepCount int
+
+	// Steps played to reach the board currently evaluated, from the root of the tree.
+	steps []int
+
+	// Transposition table containing the already processed board configurations, with their step count from the
+	// root of the tree so that it stays valid across the windows.
+	processedCache *TranspositionTable
+
+	// Statistics of the execution.
+	stats *SolverStats
+
+	// Debug statistics.
+	evaluationCounter int
+}
+
+// Recursive function searching for a solution of at most maxStepCount steps from a board, returns true if one has
+// been found. In this case, the solution is available in the context steps.
+func (ctx *SuffixImproveContext) searchCompletion(board *Board) bool {
+	// Check if the execution must be stopped.
+	select {
+	case <-ctx.done:
+		return false
+	default:
+	}
+
+	// Print debug stats.
+	ctx.stats.addEvaluation()
+	ctx.evaluationCounter++
+	if ctx.debug && ctx.evaluationCounter%100_000 == 0 {
+		log.Debug().
+			Int("max-step-count", ctx.maxStepCount).
+			Int("evaluation", ctx.evaluationCounter).
+			Int64("cache-size", ctx.processedCache.entryCounter.Load()).
+			Int64("cache-hit", ctx.processedCache.hitCounter.Load()).
+			Msg("progress")
+	}
+
+	// Check if the board is solved.
+	if board.isSolved() {
+		return true
+	}
+
+	// Check that the number of remaining colors in the board (i.e. minimum number of steps to play) allows to improve.
+	stepCount := len(ctx.steps)
+	if stepCount+board.getRemainingColorCount() > ctx.maxStepCount {
+		return false
+	}
+
+	// Check if we have already processed this board configuration with a lower or equal step count.
+	if !ctx.processedCache.markProcessed(board, stepCount) {
+		return false
+	}
+
+	// Try all the colors in the frontier and continue the search.
+	for _, color := range board.getColorsInFrontier() {
+		// Clone and update the board.
+		boardCopy := board.clone()
+		boardCopy.playStep(color)
+
+		// Continue the search.
+		ctx.steps = append(ctx.steps, color)
+		if ctx.searchCompletion(boardCopy) {
+			return true
+		}
+		ctx.steps = ctx.steps[:stepCount]
+	}
+
+	return false
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestSuffixImprove(t *testing.T) {
+	for _, testCase := range []struct {
+		inputFile string
+		optimum   int
+	}{
+		{"samples/12_12_4-1.csv", 12},
+		{"samples/12_12_5-1.csv", 14},
+	} {
+		// The search window reaches the root of the tree, the solution is then optimal.
+		solution := testImplementation(t, suffixImprove, &SolverConfig{cacheMb: 16}, testCase.inputFile)
+		if len(solution) != testCase.optimum {
+			t.Fatalf("the solution of %s is not optimal, expected=%d, actual=%d", testCase.inputFile, testCase.optimum, len(solution))
+		}
+	}
+}
+
+func BenchmarkSuffixImprove(b *testing.B) {
+	benchmarkImplementation(b, suffixImprove, &SolverConfig{}, "samples/12_12_5-1.csv")
+}
@@ -1,32 +1,85 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github.com/rs/zerolog/log"
+	"runtime"
 	"testing"
 )
 
-func benchmarkImplementation(b *testing.B, implFn AlgorithmFn, inputFile string) {
+// Run an implementation on a board input file and check that the returned solution solves the board.
+func testImplementation(t *testing.T, implFn AlgorithmFn, config *SolverConfig, inputFile string) []int {
+	board, err := readInputFile(inputFile, &InputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Discard all the solutions, only the returned one is checked.
+	onSolution := func(solution []int) {}
+	solution, err := implFn(context.Background(), board.clone(), onSolution, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report := verifySolution(board, solution); !report.solved {
+		t.Fatalf("the solution does not solve the board %s: %v", inputFile, solution)
+	}
+	return solution
+}
+
+func benchmarkImplementation(b *testing.B, implFn AlgorithmFn, config *SolverConfig, inputFile string) {
 	// Prepare the implementation parameters.
-	board, err := readInputFile(inputFile, false)
+	board, err := readInputFile(inputFile, &InputOptions{})
 	if err != nil {
 		log.Fatal().
 			Err(err).
 			Msg("unable to load the board input file")
 	}
-	solutions := make(chan []int, 100)
 
-	// Launch a go routine that consumes all the solutions.
-	go func() {
-		for {
-			_ = <-solutions
-		}
-	}()
+	// Discard all the solutions.
+	onSolution := func(solution []int) {}
 
 	// Run the implementation to benchmark b.N times.
 	for n := 0; n < b.N; n++ {
-		_, err := implFn(board.clone(), solutions, nil, false)
+		_, err := implFn(context.Background(), board.clone(), onSolution, config)
 		if err != nil {
 			log.Fatal().Err(err).Msg("error during the algorithm execution")
 		}
 	}
 }
+
+func TestParallelDeepSearch(t *testing.T) {
+	for _, testCase := range []struct {
+		inputFile string
+		optimum   int
+	}{
+		{"samples/12_12_4-1.csv", 12},
+		{"samples/12_12_5-1.csv", 14},
+	} {
+		// With several workers, the branches are shared and stolen between them.
+		for _, workers := range []int{1, 2, 4} {
+			solution := testImplementation(t, parallelDeepSearch, &SolverConfig{workers: workers, cacheMb: 16}, testCase.inputFile)
+			if len(solution) != testCase.optimum {
+				t.Fatalf("the solution of %s is not optimal, workers=%d, expected=%d, actual=%d", testCase.inputFile, workers, testCase.optimum, len(solution))
+			}
+		}
+	}
+}
+
+func BenchmarkParallelDeepSearch(b *testing.B) {
+	// Measure how the implementation scales with the number of workers: powers of 2 up to the number of CPU cores.
+	numCores := runtime.NumCPU()
+	for workers := 1; ; workers *= 2 {
+		if workers > numCores {
+			workers = numCores
+		}
+
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			benchmarkImplementation(b, parallelDeepSearch, &SolverConfig{workers: workers}, "samples/12_12_5-1.csv")
+		})
+
+		if workers == numCores {
+			break
+		}
+	}
+}
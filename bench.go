@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BenchResult is the result of an implementation execution on a board by the bench command.
+// Its fields are exported to be serialized as JSON.
+type BenchResult struct {
+	// The board input file name and the implementation name.
+	Sample         string `json:"sample"`
+	Implementation string `json:"implementation"`
+
+	// The execution wall time and whether it has been stopped by the timeout.
+	ElapsedMs int64 `json:"elapsed-ms"`
+	Timeout   bool  `json:"timeout"`
+
+	// The best solution found, nil if none has been found before the timeout.
+	Solution []int `json:"solution"`
+
+	// Statistics of the execution, see SolverStats.
+	Evaluations   int64 `json:"evaluations"`
+	OptimalProven bool  `json:"optimal-proven"`
+
+	// The error of the execution, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// BenchWriterFn is the function type writing the bench results in a given format.
+type BenchWriterFn func(writer io.Writer, results []*BenchResult) error
+
+// Available bench result formats.
+var benchWriters = map[string]BenchWriterFn{
+	"markdown": writeBenchMarkdown,
+	"csv":      writeBenchCsv,
+	"json":     writeBenchJson,
+}
+
+// Execute an implementation on a board with a timeout and return its result.
+func runBenchmark(sample string, board *Board, impl string, config *SolverConfig, timeout time.Duration) *BenchResult {
+	result := &BenchResult{Sample: sample, Implementation: impl}
+
+	// Get the algorithm implementation, with its own statistics.
+	stats := &SolverStats{}
+	solverConfig := *config
+	solverConfig.stats = stats
+	solver, err := newSolver(impl, &solverConfig)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	// Keep the best solution reported, in case the execution is stopped before returning one.
+	var bestSolutionMutex sync.Mutex
+	var bestSolution []int = nil
+	onSolution := func(solution []int) {
+		bestSolutionMutex.Lock()
+		defer bestSolutionMutex.Unlock()
+		if bestSolution == nil || len(solution) < len(bestSolution) {
+			bestSolution = solution
+		}
+	}
+
+	// Execute it.
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	start := time.Now()
+	solution, err := solver.Solve(ctx, board.clone(), onSolution)
+	result.ElapsedMs = time.Since(start).Milliseconds()
+	if errors.Is(err, context.DeadlineExceeded) {
+		result.Timeout = true
+	} else if err != nil {
+		result.Error = err.Error()
+	}
+
+	bestSolutionMutex.Lock()
+	if solution == nil || (bestSolution != nil && len(bestSolution) < len(solution)) {
+		solution = bestSolution
+	}
+	bestSolutionMutex.Unlock()
+
+	// Check the solution before recording it.
+	if solution != nil && !verifySolution(board.clone(), solution).solved {
+		result.Error = "the solution does not solve the board"
+	}
+	result.Solution = solution
+	result.Evaluations = stats.getEvaluationCount()
+	result.OptimalProven = stats.isOptimalProven() && !result.Timeout
+
+	return result
+}
+
+// Returns the solution formatted as in the README results table, e.g. "[1,0,3]".
+func formatBenchSolution(solution []int) string {
+	colors := make([]string, len(solution))
+	for i, color := range solution {
+		colors[i] = strconv.Itoa(color)
+	}
+	return "[" + strings.Join(colors, ",") + "]"
+}
+
+// Returns the elapsed time formatted like the time command output, e.g. "1m29,299s".
+func formatBenchElapsed(elapsedMs int64) string {
+	return fmt.Sprintf("%dm%d,%03ds", elapsedMs/60_000, (elapsedMs/1000)%60, elapsedMs%1000)
+}
+
+// Write the results as a markdown table with the same layout as the README results table: one row per sample and one
+// column per implementation.
+func writeBenchMarkdown(writer io.Writer, results []*BenchResult) error {
+	// Get the samples and the implementations, in the order of the results.
+	var samples, impls []string
+	cells := make(map[[2]string]string)
+	for _, result := range results {
+		key := [2]string{result.Sample, result.Implementation}
+		if _, exists := cells[key]; exists {
+			continue
+		}
+		if len(samples) == 0 || samples[len(samples)-1] != result.Sample {
+			samples = append(samples, result.Sample)
+		}
+		isNewImpl := true
+		for _, impl := range impls {
+			if impl == result.Implementation {
+				isNewImpl = false
+				break
+			}
+		}
+		if isNewImpl {
+			impls = append(impls, result.Implementation)
+		}
+
+		// Format the cell of the result.
+		elapsed := formatBenchElapsed(result.ElapsedMs)
+		if result.Timeout {
+			elapsed = "timeout"
+		} else if result.Error != "" {
+			elapsed = "error"
+		}
+		cells[key] = fmt.Sprintf("**%s** <br />nb-steps=%d <br />solution=%s <br />evaluations=%d <br />optimal=%t",
+			elapsed, len(result.Solution), formatBenchSolution(result.Solution), result.Evaluations, result.OptimalProven)
+	}
+
+	// Build the rows and compute the width of the columns.
+	rows := [][]string{append([]string{"Sample"}, impls...)}
+	for _, sample := range samples {
+		row := []string{sample}
+		for _, impl := range impls {
+			row = append(row, cells[[2]string{sample, impl}])
+		}
+		rows = append(rows, row)
+	}
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > widths[i] {
+				widths[i] = len(cell)
+			}
+		}
+	}
+
+	// Write the table, the header being followed by the separator line.
+	for rowIdx, row := range rows {
+		line := ""
+		for i, cell := range row {
+			line += fmt.Sprintf("| %-*s ", widths[i], cell)
+		}
+		if _, err := fmt.Fprintln(writer, line+"|"); err != nil {
+			return err
+		}
+
+		if rowIdx == 0 {
+			line = ""
+			for _, width := range widths {
+				line += "|" + strings.Repeat("-", width+2)
+			}
+			if _, err := fmt.Fprintln(writer, line+"|"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Write the results as CSV, one line per result.
+func writeBenchCsv(writer io.Writer, results []*BenchResult) error {
+	csvWriter := csv.NewWriter(writer)
+	_ = csvWriter.Write([]string{"sample", "implementation", "elapsed-ms", "timeout", "nb-steps", "solution", "evaluations", "optimal-proven", "error"})
+	for _, result := range results {
+		_ = csvWriter.Write([]string{
+			result.Sample,
+			result.Implementation,
+			strconv.FormatInt(result.ElapsedMs, 10),
+			strconv.FormatBool(result.Timeout),
+			strconv.Itoa(len(result.Solution)),
+			formatBenchSolution(result.Solution),
+			strconv.FormatInt(result.Evaluations, 10),
+			strconv.FormatBool(result.OptimalProven),
+			result.Error,
+		})
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// Write the results as an indented JSON array.
+func writeBenchJson(writer io.Writer, results []*BenchResult) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunBenchmark(t *testing.T) {
+	board, err := readInputFile("samples/12_12_4-1.csv", &InputOptions{checkSquare: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := runBenchmark("12_12_4-1.csv", board, "astar", &SolverConfig{}, 10*time.Second)
+	if result.Error != "" || result.Timeout {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if len(result.Solution) != 12 || !result.OptimalProven || result.Evaluations == 0 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	// The board must not be modified by the execution.
+	if board.isSolved() {
+		t.Fatal("the board has been modified")
+	}
+}
+
+func TestWriteBenchMarkdown(t *testing.T) {
+	results := []*BenchResult{
+		{Sample: "a.csv", Implementation: "deep-search", ElapsedMs: 89_299, Solution: []int{1, 0}, OptimalProven: true},
+		{Sample: "a.csv", Implementation: "beam", Timeout: true, Solution: []int{1, 0, 2}},
+		{Sample: "b.csv", Implementation: "deep-search", ElapsedMs: 42, Solution: []int{2}, OptimalProven: true},
+	}
+
+	var builder strings.Builder
+	if err := writeBenchMarkdown(&builder, results); err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the header, the separator and one row per sample.
+	lines := strings.Split(strings.TrimSpace(builder.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines, got %d:\n%s", len(lines), builder.String())
+	}
+	if !strings.HasPrefix(lines[0], "| Sample ") || !strings.Contains(lines[0], "| deep-search ") || !strings.Contains(lines[0], "| beam ") {
+		t.Fatalf("invalid header: %s", lines[0])
+	}
+	if !strings.Contains(lines[2], "**1m29,299s** <br />nb-steps=2 <br />solution=[1,0]") || !strings.Contains(lines[2], "**timeout**") {
+		t.Fatalf("invalid row: %s", lines[2])
+	}
+	if !strings.Contains(lines[3], "**0m0,042s**") {
+		t.Fatalf("invalid row: %s", lines[3])
+	}
+	for _, line := range lines[1:] {
+		if len(line) != len(lines[0]) {
+			t.Fatalf("the columns are not aligned:\n%s", builder.String())
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import "math/bits"
+
+// Bitset is a fixed size set of non-negative integers, stored as a slice of 64 bits words.
+type Bitset []uint64
+
+// Returns a new empty bitset able to contain the integers in [0, size).
+func newBitset(size int) Bitset {
+	return make(Bitset, (size+63)/64)
+}
+
+// Add an integer to the set.
+func (set Bitset) add(i int) {
+	set[i/64] |= 1 << (uint(i) % 64)
+}
+
+// Remove an integer from the set.
+func (set Bitset) remove(i int) {
+	set[i/64] &^= 1 << (uint(i) % 64)
+}
+
+// Returns whether an integer is in the set.
+func (set Bitset) contains(i int) bool {
+	return set[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+// Returns the number of integers in the set.
+func (set Bitset) count() int {
+	count := 0
+	for _, word := range set {
+		count += bits.OnesCount64(word)
+	}
+	return count
+}
+
+// Returns whether the set is empty.
+func (set Bitset) isEmpty() bool {
+	for _, word := range set {
+		if word != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns whether the set contains the same integers as another set of the same size.
+func (set Bitset) equals(other Bitset) bool {
+	for i, word := range set {
+		if word != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Call the specified function for each integer in the set, in ascending order.
+// The set must not be modified by the function, except for removing the current integer.
+func (set Bitset) forEach(fn func(i int)) {
+	for wordIdx, word := range set {
+		for word != 0 {
+			bit := bits.TrailingZeros64(word)
+			word &= word - 1
+			fn(wordIdx*64 + bit)
+		}
+	}
+}
+
+// Returns a copy of the set.
+func (set Bitset) clone() Bitset {
+	clone := make(Bitset, len(set))
+	copy(clone, set)
+	return clone
+}
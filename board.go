@@ -1,9 +1,11 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"image/color"
 	"sort"
-	"strconv"
-	"strings"
 )
 
 // Board represents the current status of the game.
@@ -14,37 +16,68 @@ type Board struct {
 	// Number of columns in the board.
 	nbCols int
 
-	// Map of the board cells with the cell ID as key and the color as value.
-	cells map[int]int
+	// Number of colors in the board, the colors are in [0, nbColors).
+	nbColors int
+
+	// Slice of the board cells colors, indexed by the cell ID (row * nbCols + col).
+	cells []int
 
 	// Set of the cells that are in the same contiguous area with the same color as the top-left cell.
 	// They do not need to be processed anymore.
-	completedCells map[int]void
+	completedCells Bitset
+
+	// Number of cells in the completedCells set.
+	completedCount int
 
 	// Set of the cells adjacent to the completedCells area.
-	// They may or may not be of the same color as the top-left cell.
-	frontierCells map[int]void
+	// They are never of the same color as the top-left cell once the frontier has been updated.
+	frontierCells Bitset
+
+	// Zobrist hash of the board configuration, see ZobristKeys, and the keys used to compute it.
+	hash     uint64
+	hashKeys *ZobristKeys
+
+	// Second Zobrist hash, computed with independent keys, used to detect the collisions of the first one.
+	verificationHash     uint64
+	verificationHashKeys *ZobristKeys
+
+	// Metadata of the board when they are known, e.g. when the board is loaded from an image or a JSON file, they
+	// are shared by the clones of the board:
+	//   - the actual colors of the board colors, nil if unknown
+	//   - the name of the board, empty if unknown
+	//   - the step count of the optimal solution, 0 if unknown
+	palette      color.Palette
+	name         string
+	knownOptimum int
 }
 
-func NewBoard(nbRows, nbCols int, cells map[int]int) *Board {
+func NewBoard(nbRows, nbCols int, cells []int) *Board {
+	// Compute the number of colors, the loaders ensure that the colors are lower than the cell count.
+	nbColors := 0
+	for _, color := range cells {
+		if color >= nbColors {
+			nbColors = color + 1
+		}
+	}
+
 	// Create the board.
+	nbCells := nbRows * nbCols
 	board := &Board{
-		nbRows:         nbRows,
-		nbCols:         nbCols,
-		cells:          cells,
-		completedCells: make(map[int]void),
-		frontierCells:  make(map[int]void),
+		nbRows:               nbRows,
+		nbCols:               nbCols,
+		nbColors:             nbColors,
+		cells:                cells,
+		completedCells:       newBitset(nbCells),
+		frontierCells:        newBitset(nbCells),
+		hashKeys:             newZobristKeys(nbCells, nbColors, zobristSeed),
+		verificationHashKeys: newZobristKeys(nbCells, nbColors, zobristVerificationSeed),
 	}
+	board.hash = board.hashKeys.colorKeys[cells[0]]
+	board.verificationHash = board.verificationHashKeys.colorKeys[cells[0]]
 
-	// Initialize the board with:
-	//   - a completed area consisting of only the top-left cell (ID = 0)
-	//   - a temporary frontier consisting of:
-	//		- the cell immediately to the right (ID = 1)
-	//		- the cell immediately to the bottom (ID = nbCols)
-	board.completedCells[0] = void{}
-
-	board.frontierCells[1] = void{}
-	board.frontierCells[nbCols] = void{}
+	// Initialize the board with an empty completed area and a temporary frontier consisting of only the top-left
+	// cell (ID = 0), it will be integrated in the completed area along with its same color neighbors.
+	board.frontierCells.add(0)
 
 	// Compute the initial frontier.
 	board.updateFrontier()
@@ -53,45 +86,26 @@ func NewBoard(nbRows, nbCols int, cells map[int]int) *Board {
 }
 
 func (board *Board) clone() *Board {
-	// Create a new board.
-	clone := &Board{
-		nbRows:         board.nbRows,
-		nbCols:         board.nbCols,
-		cells:          make(map[int]int, len(board.cells)),
-		completedCells: make(map[int]void, len(board.completedCells)),
-		frontierCells:  make(map[int]void, len(board.frontierCells)),
-	}
-
-	// Deep copy the nested data structures.
-	for cellId, color := range board.cells {
-		clone.cells[cellId] = color
-	}
-
-	for cellId := range board.completedCells {
-		clone.completedCells[cellId] = void{}
-	}
-
-	for cellId := range board.frontierCells {
-		clone.frontierCells[cellId] = void{}
-	}
-
-	return clone
-}
-
-// Return a string identifier uniquely identifying a board configuration.
-func (board *Board) getId() string {
-	// Create a string builder and add all the cells' color.
-	var builder strings.Builder
-	builder.Grow(board.nbRows * board.nbCols * 2)
-	for iRow := 0; iRow < board.nbRows; iRow++ {
-		for iCol := 0; iCol < board.nbCols; iCol++ {
-			cellId := (iRow * board.nbCols) + iCol
-			color := board.cells[cellId]
-			builder.WriteString(strconv.Itoa(color))
-			builder.WriteString(" ")
-		}
+	// Create a new board, the nested data structures being flat slices they are deep copied with a simple copy.
+	cells := make([]int, len(board.cells))
+	copy(cells, board.cells)
+
+	return &Board{
+		nbRows:               board.nbRows,
+		nbCols:               board.nbCols,
+		nbColors:             board.nbColors,
+		cells:                cells,
+		completedCells:       board.completedCells.clone(),
+		completedCount:       board.completedCount,
+		frontierCells:        board.frontierCells.clone(),
+		hash:                 board.hash,
+		hashKeys:             board.hashKeys,
+		verificationHash:     board.verificationHash,
+		verificationHashKeys: board.verificationHashKeys,
+		palette:              board.palette,
+		name:                 board.name,
+		knownOptimum:         board.knownOptimum,
 	}
-	return builder.String()
 }
 
 // Update the current frontier by looking at all the cells inside it and checking if their color is the
@@ -100,159 +114,136 @@ func (board *Board) getId() string {
 //  2. the cell is integrated in the completed area
 //  3. the adjacent cells are integrated into the frontier (if necessary)
 func (board *Board) updateFrontier() {
-	// Initialize the set of cells to process with the current frontier and clear the latter.
-	cellsToProcess := board.frontierCells
-	board.frontierCells = make(map[int]void, len(cellsToProcess))
+	// Initialize the stack of cells to process with the frontier cells having the same color as the top-left one.
+	// The cells are integrated in the completed area as soon as they are pushed to the stack, so that they are
+	// never pushed twice.
+	currentColor := board.cells[0]
+	var cellsToProcess []int
 
 	// Closure function processing one cell.
 	processCell := func(cellId int) {
 		// Check if the cell has not been already processed.
-		_, alreadyCompleted := board.completedCells[cellId]
-		if !alreadyCompleted {
-			// The cell may already be present in the cellsToProcess map, but it's ok.
-			// We save a look-up in the map by not testing the presence of the key prior to adding it.
-			cellsToProcess[cellId] = void{}
+		if board.completedCells.contains(cellId) {
+			return
+		}
+
+		// Check if the current cell has the same color as the top-left one.
+		if board.cells[cellId] == currentColor {
+			// Yes, add it to the completed area and mark it to be processed.
+			board.frontierCells.remove(cellId)
+			board.completedCells.add(cellId)
+			board.completedCount++
+			board.hash ^= board.hashKeys.cellKeys[cellId]
+			board.verificationHash ^= board.verificationHashKeys.cellKeys[cellId]
+			cellsToProcess = append(cellsToProcess, cellId)
+		} else {
+			// No, add it to the frontier.
+			board.frontierCells.add(cellId)
 		}
 	}
 
-	// Loop over the set of cells to process until it's empty.
-	currentColor := board.cells[0]
-	for {
-		// Iterate over the cells to process.
-		for cellId := range cellsToProcess {
-			// Remove it from the cells to process.
-			delete(cellsToProcess, cellId)
-
-			// Check if the current cell has the same color as the top-left one.
-			if board.cells[cellId] == currentColor {
-				// Yes, add it to the completed area and mark the adjacent cells to be processed.
-				board.completedCells[cellId] = void{}
-
-				// Add the top, bottom, left and right adjacent cells if not already processed.
-				row := cellId / board.nbCols
-				col := cellId % board.nbCols
-
-				// Top
-				if row > 0 {
-					processCell(cellId - board.nbCols)
-				}
-
-				// Bottom
-				if row < (board.nbRows - 1) {
-					processCell(cellId + board.nbCols)
-				}
-
-				// Left
-				if col > 0 {
-					processCell(cellId - 1)
-				}
-
-				// Right
-				if col < (board.nbCols - 1) {
-					processCell(cellId + 1)
-				}
-			} else {
-				// No, add it to the frontier.
-				board.frontierCells[cellId] = void{}
-			}
+	board.frontierCells.forEach(processCell)
+
+	// Loop over the stack of cells to process until it's empty.
+	for len(cellsToProcess) > 0 {
+		// Pop a cell from the stack.
+		cellId := cellsToProcess[len(cellsToProcess)-1]
+		cellsToProcess = cellsToProcess[:len(cellsToProcess)-1]
+
+		// Process the top, bottom, left and right adjacent cells.
+		row := cellId / board.nbCols
+		col := cellId % board.nbCols
+
+		// Top
+		if row > 0 {
+			processCell(cellId - board.nbCols)
 		}
 
-		if len(cellsToProcess) == 0 {
-			// No more cell to process, we are done.
-			break
+		// Bottom
+		if row < (board.nbRows - 1) {
+			processCell(cellId + board.nbCols)
 		}
-	}
-}
 
-// Returns the list of colors in the frontier ordered by descending area size.
-func (board *Board) getColorsInFrontier() []int {
-	// Compute the set of cells (areas) accessible from the frontier and grouped by color.
-	areasByColor := make(map[int]map[int]void)
+		// Left
+		if col > 0 {
+			processCell(cellId - 1)
+		}
 
-	// Initialize the set of cells to process with the current frontier.
-	cellsToProcess := make(map[int]void, len(board.frontierCells))
-	for cellId := range board.frontierCells {
-		cellsToProcess[cellId] = void{}
+		// Right
+		if col < (board.nbCols - 1) {
+			processCell(cellId + 1)
+		}
 	}
+}
 
-	// Initialized the set of processed cells to an empty set.
-	processedCells := make(map[int]void)
+// Returns the list of colors in the frontier ordered by descending area size, the ties being ordered by ascending
+// color.
+func (board *Board) getColorsInFrontier() []int {
+	// Compute the size of the areas accessible from the frontier and grouped by color.
+	areaSizeByColor := make([]int, board.nbColors)
+
+	// Initialize the stack of cells to process with the current frontier.
+	cellsToProcess := make([]int, 0, board.nbCols)
+	processedCells := board.frontierCells.clone()
+	board.frontierCells.forEach(func(cellId int) {
+		cellsToProcess = append(cellsToProcess, cellId)
+	})
 
 	// Closure function processing one cell.
 	processCell := func(cellId, expectedColor int) {
-		// Check if the cell color is the same as the expected one.
-		color := board.cells[cellId]
-		if color == expectedColor {
-			// Check if the cell has not been already processed.
-			_, alreadyProcessed := processedCells[cellId]
-			if !alreadyProcessed {
-				// The cell may already be present in the cellsToProcess map, but it's ok.
-				// We save a look-up in the map by not testing the presence of the key prior to adding it.
-				cellsToProcess[cellId] = void{}
-			}
+		// Check if the cell color is the same as the expected one and if it has not been already processed.
+		if board.cells[cellId] == expectedColor && !processedCells.contains(cellId) {
+			processedCells.add(cellId)
+			cellsToProcess = append(cellsToProcess, cellId)
 		}
 	}
 
-	// Loop over the set of cells to process until it's empty.
-	for {
-		// Iterate over the cells to process.
-		for cellId := range cellsToProcess {
-			// Remove it from the cells to process.
-			delete(cellsToProcess, cellId)
-			processedCells[cellId] = void{}
-
-			// Add it to the area corresponding to its color.
-			color := board.cells[cellId]
-			area := areasByColor[color]
-			if area == nil {
-				// Lazy initialization of the area set for this color.
-				area = make(map[int]void)
-				areasByColor[color] = area
-			}
-			area[cellId] = void{}
+	// Loop over the stack of cells to process until it's empty.
+	for len(cellsToProcess) > 0 {
+		// Pop a cell from the stack.
+		cellId := cellsToProcess[len(cellsToProcess)-1]
+		cellsToProcess = cellsToProcess[:len(cellsToProcess)-1]
 
-			// Check if the top, bottom, left and right adjacent cells are of the same color.
-			row := cellId / board.nbCols
-			col := cellId % board.nbCols
+		// Add it to the area corresponding to its color.
+		color := board.cells[cellId]
+		areaSizeByColor[color]++
 
-			// Top
-			if row > 0 {
-				processCell(cellId-board.nbCols, color)
-			}
+		// Check if the top, bottom, left and right adjacent cells are of the same color.
+		row := cellId / board.nbCols
+		col := cellId % board.nbCols
 
-			// Bottom
-			if row < (board.nbRows - 1) {
-				processCell(cellId+board.nbCols, color)
-			}
+		// Top
+		if row > 0 {
+			processCell(cellId-board.nbCols, color)
+		}
 
-			// Left
-			if col > 0 {
-				processCell(cellId-1, color)
-			}
+		// Bottom
+		if row < (board.nbRows - 1) {
+			processCell(cellId+board.nbCols, color)
+		}
 
-			// Right
-			if col < (board.nbCols - 1) {
-				processCell(cellId+1, color)
-			}
+		// Left
+		if col > 0 {
+			processCell(cellId-1, color)
 		}
 
-		if len(cellsToProcess) == 0 {
-			// No more cell to process, we are done.
-			break
+		// Right
+		if col < (board.nbCols - 1) {
+			processCell(cellId+1, color)
 		}
 	}
 
 	// Get the list of colors.
-	colors := make([]int, 0, len(areasByColor))
-	for color := range areasByColor {
-		colors = append(colors, color)
+	colors := make([]int, 0, board.nbColors)
+	for color, areaSize := range areaSizeByColor {
+		if areaSize > 0 {
+			colors = append(colors, color)
+		}
 	}
 
 	// Order it by the cell count in descending order.
-	sort.Slice(colors, func(i, j int) bool {
-		color1 := colors[i]
-		color2 := colors[j]
-		return len(areasByColor[color1]) > len(areasByColor[color2])
+	sort.SliceStable(colors, func(i, j int) bool {
+		return areaSizeByColor[colors[i]] > areaSizeByColor[colors[j]]
 	})
 
 	return colors
@@ -263,28 +254,82 @@ func (board *Board) getRemainingColors() map[int]int {
 	// Iterate over the board cells and get the colors of the not completed ones
 	remainingColors := make(map[int]int)
 	for cellId, color := range board.cells {
-		_, completed := board.completedCells[cellId]
-		if !completed {
+		if !board.completedCells.contains(cellId) {
 			remainingColors[color]++
 		}
 	}
 	return remainingColors
 }
 
+// Returns the number of remaining colors in the board, i.e. len(getRemainingColors()) without the allocation of
+// the map.
+func (board *Board) getRemainingColorCount() int {
+	remainingColors := newBitset(board.nbColors)
+	count := 0
+	for cellId, color := range board.cells {
+		if !remainingColors.contains(color) && !board.completedCells.contains(cellId) {
+			remainingColors.add(color)
+			count++
+			if count == board.nbColors {
+				break
+			}
+		}
+	}
+	return count
+}
+
 // Execute a step by:
 //  1. changing the color of all the cells inside the completed area to the specified color
 //  2. extending the current frontier
 func (board *Board) playStep(color int) {
+	// Update the hashes with the new completed area color.
+	previousColor := board.cells[0]
+	board.hash ^= board.hashKeys.colorKeys[previousColor] ^ board.hashKeys.colorKeys[color]
+	board.verificationHash ^= board.verificationHashKeys.colorKeys[previousColor] ^
+		board.verificationHashKeys.colorKeys[color]
+
 	// Update the color of all the cells in the completed area.
-	for cellId := range board.completedCells {
+	board.completedCells.forEach(func(cellId int) {
 		board.cells[cellId] = color
-	}
+	})
 
 	// Update the frontier.
 	board.updateFrontier()
 }
 
+// Execute a step like playStep and returns the IDs of the cells integrated in the completed area by it.
+func (board *Board) playStepAndGetCompletedCells(color int) []int {
+	previousCompletedCells := board.completedCells.clone()
+	board.playStep(color)
+
+	var completedCells []int
+	board.completedCells.forEach(func(cellId int) {
+		if !previousCompletedCells.contains(cellId) {
+			completedCells = append(completedCells, cellId)
+		}
+	})
+	return completedCells
+}
+
 // Returns whether the board is solved, i.e. no more cell needs to be processed.
 func (board *Board) isSolved() bool {
-	return len(board.frontierCells) == 0
+	return board.completedCount == len(board.cells)
+}
+
+// Returns whether the board has the same configuration as another board with the same initial cells, i.e. the same
+// completed area with the same color. It allows to resolve the collisions of the Zobrist hash.
+func (board *Board) isSameConfiguration(other *Board) bool {
+	return board.cells[0] == other.cells[0] && board.completedCells.equals(other.completedCells)
+}
+
+// Returns the SHA-256 hash of the size and of the cell colors of the board, as a hexadecimal string. It identifies a
+// board independently of its file format and of its metadata, e.g. to check that a saved state is about the same board.
+func (board *Board) fingerprint() string {
+	hash := sha256.New()
+	buffer := make([]byte, 4)
+	for _, value := range append([]int{board.nbRows, board.nbCols}, board.cells...) {
+		binary.LittleEndian.PutUint32(buffer, uint32(value))
+		hash.Write(buffer)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
 }
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Default interval between two checkpoints of the deep search.
+const defaultCheckpointInterval = time.Minute
+
+// Maximum duration to wait for the deep search to save its last checkpoint once the execution is stopped.
+const checkpointSaveTimeout = 30 * time.Second
+
+// Suffix of the file containing the transposition table saved along a checkpoint.
+const checkpointCacheSuffix = ".cache"
+
+// DeepSearchCheckpoint is the state of a deep search saved to be resumed by a later execution, its fields are
+// exported to be serialized. The search tree is explored in a deterministic order, so the state is fully defined by
+// the path to the next board configuration to evaluate: the branches preceding it have all been explored.
+type DeepSearchCheckpoint struct {
+	// Fingerprint of the board, see Board.fingerprint.
+	Board string `json:"board"`
+
+	// Best solution found and its step count, i.e. the bound used to prune the search tree.
+	BestSolution  []int `json:"best-solution"`
+	BestStepCount int   `json:"best-step-count"`
+
+	// Steps leading to the next board configuration to evaluate.
+	Path []int `json:"path"`
+
+	// Whether the search tree has been fully explored, the best solution is then optimal if the executions verified
+	// the transposition table entries.
+	Completed bool `json:"completed"`
+
+	// Whether all the executions verified the transposition table entries, see SolverConfig.cacheVerify.
+	Verified bool `json:"verified"`
+
+	// Number of board configurations evaluated by all the executions.
+	Evaluations int64 `json:"evaluations"`
+
+	// Whether the transposition table has been saved along, in the file with the checkpointCacheSuffix suffix.
+	Cache bool `json:"cache"`
+}
+
+// DeepSearchCheckpointer periodically saves the state of a deep search to a file, see DeepSearchCheckpoint.
+type DeepSearchCheckpointer struct {
+	// File in which the state is saved and whether the transposition table is saved along.
+	filePath     string
+	includeCache bool
+
+	// Interval between two checkpoints and time of the last one.
+	interval     time.Duration
+	lastSaveTime time.Time
+
+	// Fingerprint of the board, see Board.fingerprint.
+	boardFingerprint string
+
+	// Number of board configurations evaluated by the previous executions.
+	previousEvaluations int64
+
+	// Whether this execution and the previous ones verified the transposition table entries.
+	verified bool
+}
+
+// Returns a new checkpointer of the search of the board, nil if the checkpoint file is not specified.
+func newDeepSearchCheckpointer(board *Board, config *SolverConfig) *DeepSearchCheckpointer {
+	if config.checkpointFile == "" {
+		return nil
+	}
+
+	interval := config.checkpointInterval
+	if interval <= 0 {
+		interval = defaultCheckpointInterval
+	}
+	return &DeepSearchCheckpointer{
+		filePath:         config.checkpointFile,
+		includeCache:     config.checkpointCache,
+		interval:         interval,
+		lastSaveTime:     time.Now(),
+		boardFingerprint: board.fingerprint(),
+		verified:         config.cacheVerify,
+	}
+}
+
+// Load the checkpoint to resume from, and the transposition table if it has been saved along and the table is not nil.
+// Returns nil if there is no checkpoint file yet.
+func (checkpointer *DeepSearchCheckpointer) load(cache *TranspositionTable) (*DeepSearchCheckpoint, error) {
+	checkpointBytes, err := os.ReadFile(checkpointer.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read the checkpoint file: %w", err)
+	}
+
+	var checkpoint DeepSearchCheckpoint
+	if err := json.Unmarshal(checkpointBytes, &checkpoint); err != nil {
+		return nil, fmt.Errorf("unable to parse the checkpoint file: %w", err)
+	}
+	if checkpoint.Board != checkpointer.boardFingerprint {
+		return nil, fmt.Errorf("the checkpoint file is about another board")
+	}
+	checkpointer.previousEvaluations = checkpoint.Evaluations
+	checkpointer.verified = checkpointer.verified && checkpoint.Verified
+
+	// Restore the transposition table, it's only an optimization so an invalid one is reset and ignored.
+	if checkpoint.Cache && cache != nil {
+		if err := readCheckpointCache(checkpointer.filePath+checkpointCacheSuffix, cache); err != nil {
+			log.Warn().Err(err).Msg("unable to restore the transposition table of the checkpoint, it's ignored")
+			cache.reset()
+		}
+	}
+
+	return &checkpoint, nil
+}
+
+// Save the state of the search if the interval since the last checkpoint has elapsed.
+func (checkpointer *DeepSearchCheckpointer) saveIfDue(ctx *DeepSearchContext, path []int) {
+	if time.Since(checkpointer.lastSaveTime) >= checkpointer.interval {
+		checkpointer.save(ctx, path, false)
+	}
+}
+
+// Save the state of the search, the path being the steps of the next board configuration to evaluate. The errors are
+// logged but not returned, as the search can continue without checkpoints.
+func (checkpointer *DeepSearchCheckpointer) save(ctx *DeepSearchContext, path []int, completed bool) {
+	checkpointer.lastSaveTime = time.Now()
+	checkpoint := &DeepSearchCheckpoint{
+		Board:         checkpointer.boardFingerprint,
+		BestSolution:  ctx.bestSolution,
+		BestStepCount: ctx.bestSolutionStepCount,
+		Path:          path,
+		Completed:     completed,
+		Verified:      checkpointer.verified,
+		Evaluations:   checkpointer.previousEvaluations + int64(ctx.evaluationCounter),
+		Cache:         checkpointer.includeCache && !completed,
+	}
+
+	// Save the transposition table first, so that the checkpoint file never references a missing or outdated one.
+	if checkpoint.Cache {
+		err := writeFileAtomically(checkpointer.filePath+checkpointCacheSuffix, ctx.processedCache.writeTo)
+		if err != nil {
+			log.Error().Err(err).Msg("unable to save the transposition table of the checkpoint")
+			checkpoint.Cache = false
+		}
+	}
+	err := writeFileAtomically(checkpointer.filePath, func(writer io.Writer) error {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(checkpoint)
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("unable to save the checkpoint")
+		return
+	}
+	if completed {
+		// The transposition table is not needed anymore.
+		_ = os.Remove(checkpointer.filePath + checkpointCacheSuffix)
+	}
+
+	log.Info().
+		Str("file", checkpointer.filePath).
+		Int("best", checkpoint.BestStepCount).
+		Int("depth", len(path)).
+		Bool("completed", completed).
+		Msg("checkpoint saved")
+}
+
+// Restore a transposition table saved along a checkpoint.
+func readCheckpointCache(filePath string, cache *TranspositionTable) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return cache.readFrom(f)
+}
+
+// Write a file using the write function, the content being written to a temporary file first and then renamed so
+// that the file is never partially written, e.g. if the process is killed.
+func writeFileAtomically(filePath string, writeFn func(writer io.Writer) error) error {
+	f, err := os.CreateTemp(filepath.Dir(filePath), filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	err = f.Chmod(0644)
+	if err == nil {
+		err = writeFn(f)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filePath)
+}
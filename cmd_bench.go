@@ -0,0 +1,122 @@
+package main
+
+import (
+	"flag"
+	"github.com/rs/zerolog/log"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Command executing a set of implementations on the board input files matching some glob patterns and writing the
+// results as a table in the same layout as the README results table, or as CSV or JSON.
+func benchCommand(args []string) int {
+	// Parse the command line arguments.
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	debug := flags.Bool("debug", false, "Enable the debug logs")
+	impls := flags.String("impls", "deep-search", "Comma separated names of the algorithm implementations to execute, 'all' for all of them")
+	inputOptions := addInputFlags(flags)
+	timeoutSec := flags.Int("timeout", 115, "Timeout in seconds of each execution")
+	format := flags.String("format", "markdown", "Format of the results: markdown, csv or json")
+	outputFile := flags.String("output", "", "File path in which to write the results, default is stdout")
+	cacheMb := flags.Int("cache-mb", 0, "Size in megabytes of the transposition tables used by the deep search implementations, 0 means a size depending on the board size")
+	cacheVerify := flags.Bool("cache-verify", false, "Verify the board configuration of the cache entries to detect the hash collisions")
+	workers := flags.Int("workers", 0, "Number of workers used by the parallel implementations, 0 means one per CPU core")
+	_ = flags.Parse(args)
+
+	configureLogging(*debug)
+
+	// Get the implementations to execute.
+	implNames := strings.Split(*impls, ",")
+	if *impls == "all" {
+		implNames = getImplementationNames()
+	}
+	for _, impl := range implNames {
+		if _, exists := implementations[impl]; !exists {
+			log.Error().
+				Str("selected", impl).
+				Strs("available", getImplementationNames()).
+				Msg("invalid algorithm implementation specified")
+			return 2
+		}
+	}
+
+	writerFn, exists := benchWriters[*format]
+	if !exists {
+		log.Error().Str("format", *format).Msg("invalid results format specified")
+		return 2
+	}
+
+	// Get the board input files matching the patterns.
+	var inputFiles []string
+	for _, pattern := range flags.Args() {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			log.Error().Err(err).Str("pattern", pattern).Msg("invalid board input file pattern")
+			return 2
+		}
+		inputFiles = append(inputFiles, matches...)
+	}
+	sort.Strings(inputFiles)
+	if len(inputFiles) == 0 {
+		log.Error().Strs("patterns", flags.Args()).Msg("no board input file matching the patterns")
+		return 2
+	}
+
+	// Execute the implementations on each board, one at a time so that they don't compete for the CPU.
+	config := &SolverConfig{debug: *debug, workers: *workers, cacheMb: *cacheMb, cacheVerify: *cacheVerify}
+	timeout := time.Duration(*timeoutSec) * time.Second
+	var results []*BenchResult
+	exitCode := 0
+	for _, inputFile := range inputFiles {
+		board, err := readInputFile(inputFile, inputOptions)
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("input-file", inputFile).
+				Msg("unable to load the board input file")
+			return 2
+		}
+
+		for _, impl := range implNames {
+			result := runBenchmark(filepath.Base(inputFile), board, impl, config, timeout)
+			results = append(results, result)
+
+			event := log.Info()
+			if result.Error != "" {
+				event = log.Error().Str("error", result.Error)
+				exitCode = 1
+			}
+			event.
+				Str("sample", result.Sample).
+				Str("impl", impl).
+				Int64("elapsed-ms", result.ElapsedMs).
+				Bool("timeout", result.Timeout).
+				Int("nb-steps", len(result.Solution)).
+				Int64("evaluations", result.Evaluations).
+				Bool("optimal", result.OptimalProven).
+				Msg("benchmark finished")
+		}
+	}
+
+	// Write the results.
+	var writer io.Writer = os.Stdout
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			log.Error().Err(err).Str("output-file", *outputFile).Msg("unable to create the results file")
+			return 1
+		}
+		defer file.Close()
+		writer = file
+	}
+	if err := writerFn(writer, results); err != nil {
+		log.Error().Err(err).Msg("unable to write the results")
+		return 1
+	}
+
+	return exitCode
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+// Command replaying a solution file on a board input file and exporting the replay as images: a PNG image per step,
+// an animated GIF and an SVG drawing.
+func exportCommand(args []string) int {
+	// Parse the command line arguments.
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	debug := flags.Bool("debug", false, "Enable the debug logs")
+	inputOptions := addInputFlags(flags)
+	solutionFile := flags.String("solution", "", "File path of the solution to replay")
+	paletteSpec := flags.String("palette", "", "Comma separated RGB hexadecimal colors of the board colors, e.g. ff0000,00ff00,0000ff")
+	cellSize := flags.Int("cell-size", 20, "Size in pixels of the cells")
+	pngDir := flags.String("png-dir", "", "Directory in which to write a PNG image of the board at each step")
+	gifFile := flags.String("gif", "", "File path in which to write the animated GIF of the replay")
+	gifDelay := flags.Int("gif-delay", 50, "Delay in hundredths of a second between the frames of the animated GIF")
+	svgFile := flags.String("svg", "", "File path in which to write the SVG drawing of the board, labelled with the step integrating each region")
+	_ = flags.Parse(args)
+
+	inputFile := flags.Arg(0)
+
+	configureLogging(*debug)
+
+	// Load the board input file and the solution file.
+	board, err := readInputFile(inputFile, inputOptions)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("input-file", inputFile).
+			Msg("unable to load the board input file")
+		return 2
+	}
+
+	solution, err := readSolutionFile(*solutionFile)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("solution-file", *solutionFile).
+			Msg("unable to load the solution file")
+		return 2
+	}
+
+	// Use the palette of the board by default if it's known, e.g. if it has been loaded from an image.
+	palette, err := parseImagePalette(*paletteSpec)
+	if err != nil {
+		log.Error().Err(err).Msg("invalid palette specified")
+		return 2
+	}
+	if *paletteSpec == "" && board.palette != nil {
+		palette = board.palette
+	}
+	if *cellSize <= 0 {
+		log.Error().Int("cell-size", *cellSize).Msg("invalid cell size specified")
+		return 2
+	}
+
+	// Replay the solution.
+	images, err := renderSolutionImages(board, solution, palette, *cellSize)
+	if err != nil {
+		log.Error().Err(err).Msg("unable to replay the solution")
+		return 2
+	}
+
+	// Write the images.
+	if *pngDir != "" {
+		if err := writeStepPngFiles(*pngDir, images); err != nil {
+			log.Error().Err(err).Str("png-dir", *pngDir).Msg("unable to write the PNG images")
+			return 1
+		}
+		log.Info().Int("count", len(images)).Str("png-dir", *pngDir).Msg("PNG images written")
+	}
+
+	if *gifFile != "" {
+		file, err := os.Create(*gifFile)
+		if err == nil {
+			err = writeAnimatedGif(file, images, *gifDelay)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			log.Error().Err(err).Str("gif-file", *gifFile).Msg("unable to write the animated GIF")
+			return 1
+		}
+		log.Info().Int("frames", len(images)).Str("gif-file", *gifFile).Msg("animated GIF written")
+	}
+
+	if *svgFile != "" {
+		file, err := os.Create(*svgFile)
+		if err == nil {
+			err = writeSolutionSvg(file, board, solution, palette, *cellSize)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			log.Error().Err(err).Str("svg-file", *svgFile).Msg("unable to write the SVG drawing")
+			return 1
+		}
+		log.Info().Str("svg-file", *svgFile).Msg("SVG drawing written")
+	}
+
+	return 0
+}
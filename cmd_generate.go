@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"os"
+	"sort"
+	"time"
+)
+
+// Command generating a board and writing it as CSV, in the same format as the samples, or as JSON.
+func generateCommand(args []string) int {
+	// Get the generator names for the usage message.
+	patterns := make([]string, 0, len(boardGenerators))
+	for pattern := range boardGenerators {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	// Parse the command line arguments.
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	debug := flags.Bool("debug", false, "Enable the debug logs")
+	nbRows := flags.Int("rows", 30, "Number of rows of the board")
+	nbCols := flags.Int("cols", 30, "Number of columns of the board")
+	nbColors := flags.Int("colors", 6, "Number of colors of the board")
+	seed := flags.Int64("seed", 0, "Seed of the random number generator, 0 means a time based seed")
+	pattern := flags.String("pattern", "random", fmt.Sprintf("Board generator to use, one of %v", patterns))
+	outputFile := flags.String("output", "", "File path in which to write the board, as JSON if its extension is .json, default is stdout")
+	_ = flags.Parse(args)
+
+	configureLogging(*debug)
+
+	// Generate the board.
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	board, err := generateBoard(*pattern, *nbRows, *nbCols, *nbColors, *seed)
+	if err != nil {
+		log.Error().Err(err).Msg("unable to generate the board")
+		return 2
+	}
+	log.Info().
+		Str("pattern", *pattern).
+		Int("rows", *nbRows).
+		Int("cols", *nbCols).
+		Int("colors", *nbColors).
+		Int64("seed", *seed).
+		Msg("board generated")
+
+	// Write it, as JSON if the output file has the JSON extension.
+	serializeFn := serializeBoardToCsv
+	if *outputFile != "" && getFileFormat(*outputFile) == "json" {
+		board.name = fmt.Sprintf("%s-%d", *pattern, *seed)
+		serializeFn = serializeBoardToJson
+	}
+	boardStr, err := serializeFn(board)
+	if err != nil {
+		log.Error().Err(err).Msg("unable to serialize the board")
+		return 1
+	}
+
+	if *outputFile == "" {
+		fmt.Print(boardStr)
+	} else if err := os.WriteFile(*outputFile, []byte(boardStr), 0644); err != nil {
+		log.Error().Err(err).Str("output-file", *outputFile).Msg("unable to write the board to the output file")
+		return 1
+	}
+
+	return 0
+}
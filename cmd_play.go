@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Command playing a board interactively in the terminal. The keystrokes are read line by line from the standard
+// input: each number plays the corresponding color, e.g. "1 12" plays the colors 1 and 12, 'u' undoes the last step,
+// 'h' asks the hint implementation for the next color to play and 'q' quits. A line "h <impl>" asks the specified
+// implementation for a hint.
+func playCommand(args []string) int {
+	// Parse the command line arguments.
+	flags := flag.NewFlagSet("play", flag.ExitOnError)
+	debug := flags.Bool("debug", false, "Enable the debug logs")
+	inputOptions := addInputFlags(flags)
+	impl := flags.String("impl", "deep-search", "Name of the algorithm implementation computing the best known solution in the background")
+	timeoutSec := flags.Int("timeout", 115, "Timeout in seconds of the best known solution computation")
+	hintImpl := flags.String("hint-impl", "max-area-deep", "Name of the algorithm implementation used by default for the hints")
+	hintTimeoutSec := flags.Int("hint-timeout", 5, "Timeout in seconds of the hint computations")
+	_ = flags.Parse(args)
+
+	inputFile := flags.Arg(0)
+
+	// Only log the warnings and errors by default so that they don't interfere with the board display.
+	configureLogging(*debug)
+	if !*debug {
+		zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	}
+
+	// Load the board input file.
+	board, err := readInputFile(inputFile, inputOptions)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("input-file", inputFile).
+			Msg("unable to load the board input file")
+		return 2
+	}
+
+	// Compute the best known solution in the background.
+	solver, err := newSolver(*impl, &SolverConfig{})
+	if err != nil {
+		log.Error().Err(err).Str("selected", *impl).Msg("invalid algorithm implementation specified")
+		return 2
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*timeoutSec)*time.Second)
+	defer cancel()
+	var bestKnownStepCount atomic.Int64
+	go func() {
+		_, _ = solver.Solve(ctx, board.clone(), func(solution []int) {
+			// Only keep the step count of the best solution.
+			for {
+				stepCount := bestKnownStepCount.Load()
+				if (stepCount != 0 && stepCount <= int64(len(solution))) ||
+					bestKnownStepCount.CompareAndSwap(stepCount, int64(len(solution))) {
+					return
+				}
+			}
+		})
+	}()
+
+	// Play the board, the previous configurations are kept to be able to undo the steps.
+	history := []*Board{board}
+	var steps []int
+	message := ""
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		current := history[len(history)-1]
+
+		// Display the board and its status.
+		fmt.Print(ansiClearScreen)
+		fmt.Print(renderBoardAnsi(current, true))
+		fmt.Println(renderColorLegendAnsi(current.nbColors))
+		bestKnown := "computing..."
+		if stepCount := bestKnownStepCount.Load(); stepCount != 0 {
+			bestKnown = fmt.Sprintf("%d", stepCount)
+		}
+		fmt.Printf("Steps: %d, best known solution (%s): %s\n", len(steps), *impl, bestKnown)
+		if current.isSolved() {
+			fmt.Printf("Board solved in %d steps: %v\n", len(steps), steps)
+		}
+		if message != "" {
+			fmt.Println(message)
+			message = ""
+		}
+		fmt.Print("Color to play, (u)ndo, (h)int [impl], (q)uit > ")
+
+		// Read the next keystrokes.
+		if !scanner.Scan() {
+			fmt.Println()
+			return 0
+		}
+		line := strings.TrimSpace(scanner.Text())
+
+		// Check if a hint is asked to a specific implementation.
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "h" {
+			message = computeHint(current, fields[1], time.Duration(*hintTimeoutSec)*time.Second)
+			continue
+		}
+
+		keystrokes := []rune(line)
+		for i := 0; i < len(keystrokes); i++ {
+			keystroke := keystrokes[i]
+			current = history[len(history)-1]
+			switch {
+			case keystroke == 'q':
+				return 0
+			case keystroke == 'u':
+				if len(steps) > 0 {
+					history = history[:len(history)-1]
+					steps = steps[:len(steps)-1]
+				}
+			case keystroke == 'h':
+				message = computeHint(current, *hintImpl, time.Duration(*hintTimeoutSec)*time.Second)
+			case isDigitKeystroke(keystroke):
+				// Read the whole color number, the consecutive digits being a single color.
+				end := i + 1
+				for end < len(keystrokes) && isDigitKeystroke(keystrokes[end]) {
+					end++
+				}
+				colorStr := string(keystrokes[i:end])
+				i = end - 1
+
+				// Check that the color is in the frontier before playing it.
+				color, err := strconv.Atoi(colorStr)
+				playable := false
+				for _, frontierColor := range current.getColorsInFrontier() {
+					playable = playable || (err == nil && frontierColor == color)
+				}
+				if !playable {
+					message = fmt.Sprintf("The color %s is not in the frontier", colorStr)
+					continue
+				}
+
+				boardCopy := current.clone()
+				boardCopy.playStep(color)
+				history = append(history, boardCopy)
+				steps = append(steps, color)
+			case keystroke != ' ':
+				message = fmt.Sprintf("Unknown keystroke %q", keystroke)
+			}
+		}
+	}
+}
+
+// Returns whether a keystroke is a digit of a color number.
+func isDigitKeystroke(keystroke rune) bool {
+	return keystroke >= '0' && keystroke <= '9'
+}
+
+// Returns a message containing the next color to play according to an implementation.
+func computeHint(board *Board, impl string, timeout time.Duration) string {
+	if board.isSolved() {
+		return "The board is already solved"
+	}
+
+	solver, err := newSolver(impl, &SolverConfig{})
+	if err != nil {
+		return err.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	solution, _ := solver.Solve(ctx, board.clone(), func(solution []int) {})
+	if len(solution) == 0 {
+		return fmt.Sprintf("No hint from %s before the timeout", impl)
+	}
+	return fmt.Sprintf("Hint from %s: play %d, the board is then solved in %d more steps", impl, solution[0], len(solution)-1)
+}
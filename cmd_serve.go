@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Command exposing an HTTP API to solve boards with a bounded pool of workers, see SolverServer.
+func serveCommand(args []string) int {
+	// Parse the command line arguments.
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	debug := flags.Bool("debug", false, "Enable the debug logs")
+	address := flags.String("address", ":8080", "Address on which the HTTP API listens")
+	jobWorkers := flags.Int("jobs", 1, "Number of jobs executed concurrently")
+	queueSize := flags.Int("queue", 100, "Maximum number of jobs waiting for a worker, the submissions are rejected beyond")
+	timeoutSec := flags.Int("timeout", 115, "Default timeout in seconds of the jobs")
+	maxTimeoutSec := flags.Int("max-timeout", 3600, "Maximum timeout in seconds of the jobs")
+	retentionSec := flags.Int("retention", 3600, "Duration in seconds during which the finished jobs are kept")
+	cacheMb := flags.Int("cache-mb", defaultJobCacheMb, "Maximum size in megabytes of the transposition table of each job, the tables of the small boards being smaller")
+	cacheVerify := flags.Bool("cache-verify", false, "Verify the board configuration of the cache entries to detect the hash collisions")
+	workers := flags.Int("workers", 0, "Number of workers used by the parallel implementations, 0 means one per CPU core")
+	_ = flags.Parse(args)
+
+	configureLogging(*debug)
+
+	if *jobWorkers <= 0 || *queueSize < 0 || *timeoutSec <= 0 || *maxTimeoutSec < *timeoutSec {
+		log.Error().
+			Int("jobs", *jobWorkers).
+			Int("queue", *queueSize).
+			Int("timeout", *timeoutSec).
+			Int("max-timeout", *maxTimeoutSec).
+			Msg("invalid server parameters")
+		return 2
+	}
+
+	// Start the workers, they are stopped on SIGINT or SIGTERM along with the running jobs.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := newSolverServer(
+		ctx,
+		&SolverConfig{debug: *debug, workers: *workers, cacheMb: *cacheMb, cacheVerify: *cacheVerify},
+		*jobWorkers,
+		*queueSize,
+		time.Duration(*timeoutSec)*time.Second,
+		time.Duration(*maxTimeoutSec)*time.Second,
+		time.Duration(*retentionSec)*time.Second,
+	)
+
+	// Serve the API until a signal is received.
+	httpServer := &http.Server{Addr: *address, Handler: server.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		log.Info().Msg("stopping the server")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Warn().Err(err).Msg("unable to gracefully stop the server")
+		}
+	}()
+	log.Info().Str("address", *address).Int("jobs", *jobWorkers).Msg("server started")
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Error().Err(err).Msg("unable to serve the HTTP API")
+		return 1
+	}
+
+	return 0
+}
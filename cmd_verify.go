@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"github.com/rs/zerolog/log"
+)
+
+// Command replaying a solution file on a board input file and checking that it solves the board.
+func verifyCommand(args []string) int {
+	// Parse the command line arguments.
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	debug := flags.Bool("debug", false, "Enable the debug logs")
+	inputOptions := addInputFlags(flags)
+	solutionFile := flags.String("solution", "", "File path of the solution to verify")
+	_ = flags.Parse(args)
+
+	inputFile := flags.Arg(0)
+
+	configureLogging(*debug)
+
+	// Load the board input file and the solution file.
+	board, err := readInputFile(inputFile, inputOptions)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("input-file", inputFile).
+			Msg("unable to load the board input file")
+		return 2
+	}
+
+	solution, err := readSolutionFile(*solutionFile)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("solution-file", *solutionFile).
+			Msg("unable to load the solution file")
+		return 2
+	}
+
+	// Replay the solution and print the report.
+	report := verifySolution(board, solution)
+	for i, step := range report.steps {
+		event := log.Info()
+		if !step.valid || step.noOp {
+			event = log.Warn()
+		}
+		event.
+			Int("step", i+1).
+			Int("color", step.color).
+			Bool("valid", step.valid).
+			Bool("no-op", step.noOp).
+			Int("area-gained", step.areaGained).
+			Int("completed", step.completedCount).
+			Msg("step played")
+	}
+
+	if !report.solved {
+		log.Error().
+			Int("nb-steps", len(solution)).
+			Int("stall-step", report.stallStepCount).
+			Int("remaining-cells", report.remainingCellCount).
+			Msg("the solution does not solve the board")
+		return 1
+	}
+
+	log.Info().
+		Int("nb-steps", len(solution)).
+		Int("solved-step", report.solvedStepCount).
+		Msg("the solution solves the board")
+	return 0
+}
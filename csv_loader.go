@@ -3,51 +3,140 @@ package main
 import (
 	"bytes"
 	"encoding/csv"
+	"flag"
 	"fmt"
 	"github.com/rs/zerolog/log"
+	"io"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 )
 
-// Read the input CSV file specified by its path and load a board from its content.
-func readInputFile(filePath string, checkSquare bool) (*Board, error) {
-	// Load the raw string file content.
-	f, err := os.Open(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("unable to open the input file: %w", err)
+// InputOptions contains the options used to load a board input file.
+type InputOptions struct {
+	// Whether to check that the board is a square after loading it.
+	checkSquare bool
+
+	// Number of rows and columns of the board in an image input file, 0 to detect them from the image.
+	nbRows int
+	nbCols int
+
+	// Format of the input file: csv, json or image, empty to detect it from the file extension.
+	format string
+}
+
+// Returns the format of a file from its extension: csv, json or image.
+func getFileFormat(filePath string) string {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json":
+		return "json"
+	case ".png", ".gif", ".jpg", ".jpeg":
+		return "image"
+	default:
+		return "csv"
 	}
-	defer func(f *os.File) {
-		err := f.Close()
+}
+
+// Register the command line flags of the input options.
+func addInputFlags(flags *flag.FlagSet) *InputOptions {
+	options := &InputOptions{}
+	flags.BoolVar(&options.checkSquare, "check-square", true, "Check whether the board is a square after loading it")
+	flags.IntVar(&options.nbRows, "rows", 0, "Number of rows of the board in an image input file, 0 to detect it")
+	flags.IntVar(&options.nbCols, "cols", 0, "Number of columns of the board in an image input file, 0 to detect it")
+	flags.StringVar(&options.format, "input-format", "", "Format of the input file: csv, json or image, detected from the file extension by default")
+	return options
+}
+
+// Read the input file specified by its path and load a board from its content.
+// The file is either a CSV file, a JSON file or a PNG, GIF or JPEG image, depending on the input format.
+func readInputFile(filePath string, options *InputOptions) (*Board, error) {
+	// Load the raw string file content, "-" being the standard input.
+	f := os.Stdin
+	if filePath != "-" {
+		var err error
+		f, err = os.Open(filePath)
 		if err != nil {
-			log.Fatal().Err(err).Msg("unable to close the input file")
+			return nil, fmt.Errorf("unable to open the input file: %w", err)
 		}
-	}(f)
+		defer func(f *os.File) {
+			err := f.Close()
+			if err != nil {
+				log.Fatal().Err(err).Msg("unable to close the input file")
+			}
+		}(f)
+	}
 
-	records, err := csv.NewReader(f).ReadAll()
+	// Parse it, the standard input being CSV unless the format is specified.
+	format := options.format
+	if format == "" {
+		format = getFileFormat(filePath)
+	}
+	var err error
+	var board *Board
+	switch format {
+	case "csv":
+		board, err = parseCsvBoard(f)
+	case "json":
+		board, err = parseJsonBoard(f)
+	case "image":
+		board, err = parseImageBoard(f, options.nbRows, options.nbCols)
+		if err == nil {
+			// Report the palette so that the solutions can be translated back to the image colors.
+			log.Info().Int("rows", board.nbRows).Int("cols", board.nbCols).Msg("board loaded from the image")
+			for boardColor, imageColor := range board.palette {
+				log.Info().Int("color", boardColor).Str("rgb", formatHexColor(imageColor)).Msg("palette color")
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid input format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Check that the board is a square.
+	if options.checkSquare && board.nbRows != board.nbCols {
+		return nil, fmt.Errorf("invalid row and column count, the board must be a square")
+	}
+
+	return board, nil
+}
+
+// Load a board from CSV content, one row per line.
+func parseCsvBoard(reader io.Reader) (*Board, error) {
+	records, err := csv.NewReader(reader).ReadAll()
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse the input CSV file: %w", err)
 	}
 
-	// Parse it.
-	cells := make(map[int]int)
+	// Parse it, the colors must be lower than the cell count, the records all having the same field count.
+	var cells []int
+	nbCells := 0
+	if len(records) > 0 {
+		nbCells = len(records) * len(records[0])
+	}
 	for iRow, columns := range records {
 		for iCol, colorStr := range columns {
-			cellId := (iRow * len(columns)) + iCol
 			color, err := strconv.Atoi(colorStr)
 			if err != nil {
 				return nil, fmt.Errorf("invalid color for row=%d, col=%d, color=%s : %w", iRow+1, iCol+1, colorStr, err)
 			}
-			cells[cellId] = color
+			if color < 0 {
+				return nil, fmt.Errorf("invalid negative color for row=%d, col=%d, color=%d", iRow+1, iCol+1, color)
+			}
+			if color >= nbCells {
+				return nil, fmt.Errorf("invalid color for row=%d, col=%d, color=%d, it must be lower than the cell count", iRow+1, iCol+1, color)
+			}
+			cells = append(cells, color)
 		}
 	}
+	if len(cells) == 0 {
+		return nil, fmt.Errorf("invalid empty board")
+	}
 
-	// Check that the board is a square.
 	nbRows := len(records)
 	nbCols := len(cells) / nbRows
-	if checkSquare && len(cells) != (nbRows*nbRows) {
-		return nil, fmt.Errorf("invalid row and column count, the board must be a square")
-	}
-
 	return NewBoard(nbRows, nbCols, cells), nil
 }
 
@@ -81,6 +170,48 @@ func serializeBoardToCsv(board *Board) (string, error) {
 	return csvStr, nil
 }
 
+// Read a solution CSV file, in the format written by writeOutputFile, i.e. one step color per line, or a JSON result
+// file written by writeResultFile.
+func readSolutionFile(filePath string) ([]int, error) {
+	// Load the raw string file content.
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the solution file: %w", err)
+	}
+	defer func(f *os.File) {
+		err := f.Close()
+		if err != nil {
+			log.Fatal().Err(err).Msg("unable to close the solution file")
+		}
+	}(f)
+
+	// Check if it's a JSON result file.
+	if getFileFormat(filePath) == "json" {
+		return parseJsonSolution(f)
+	}
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the solution CSV file: %w", err)
+	}
+
+	// Parse it.
+	steps := make([]int, 0, len(records))
+	for iRow, columns := range records {
+		if len(columns) != 1 {
+			return nil, fmt.Errorf("invalid step #%d, a single color is expected per line", iRow+1)
+		}
+		color, err := strconv.Atoi(columns[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid color for step #%d, color=%s : %w", iRow+1, columns[0], err)
+		}
+		steps = append(steps, color)
+	}
+
+	return steps, nil
+}
+
+// Write a solution to a CSV file, one step color per line.
 func writeOutputFile(fileName string, steps []int) error {
 	// Open the file for writing.
 	f, err := os.Create(fileName)
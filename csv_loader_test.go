@@ -0,0 +1,31 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCsvBoard(t *testing.T) {
+	board, err := parseCsvBoard(strings.NewReader("0,1,2\n2,1,0\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.nbRows != 2 || board.nbCols != 3 || board.nbColors != 3 {
+		t.Fatalf("unexpected board %+v", board)
+	}
+}
+
+func TestParseCsvBoardErrors(t *testing.T) {
+	for _, boardCsv := range []string{
+		"",
+		"0,1\n1\n",
+		"0,a\n1,0\n",
+		"0,-1\n1,0\n",
+		"0,1\n1,4\n",
+		"0,1\n1,999999999\n",
+	} {
+		if _, err := parseCsvBoard(strings.NewReader(boardCsv)); err == nil {
+			t.Fatalf("the board should be rejected: %q", boardCsv)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// BoardGeneratorFn is the function type generating the cells colors of a board, the colors being in [0, nbColors).
+type BoardGeneratorFn func(nbRows, nbCols, nbColors int, random *rand.Rand) []int
+
+// Available board generators.
+var boardGenerators = map[string]BoardGeneratorFn{
+	"random":       generateRandomCells,
+	"blobs":        generateBlobCells,
+	"stripes":      generateStripeCells,
+	"checkerboard": generateCheckerboardCells,
+	"maze":         generateMazeCells,
+}
+
+// Generate a board using the generator specified by its name.
+func generateBoard(pattern string, nbRows, nbCols, nbColors int, seed int64) (*Board, error) {
+	generatorFn, exists := boardGenerators[pattern]
+	if !exists {
+		return nil, fmt.Errorf("invalid board generator %q", pattern)
+	}
+	if nbRows <= 0 || nbCols <= 0 || nbColors <= 0 {
+		return nil, fmt.Errorf("invalid board size, rows=%d, cols=%d, colors=%d", nbRows, nbCols, nbColors)
+	}
+	if nbColors > nbRows*nbCols {
+		return nil, fmt.Errorf("invalid color count, rows=%d, cols=%d, colors=%d, it must not exceed the cell count", nbRows, nbCols, nbColors)
+	}
+
+	cells := generatorFn(nbRows, nbCols, nbColors, rand.New(rand.NewSource(seed)))
+	return NewBoard(nbRows, nbCols, cells), nil
+}
+
+// Generate cells with uniformly distributed random colors, like the contest samples.
+func generateRandomCells(nbRows, nbCols, nbColors int, random *rand.Rand) []int {
+	cells := make([]int, nbRows*nbCols)
+	for cellId := range cells {
+		cells[cellId] = random.Intn(nbColors)
+	}
+	return cells
+}
+
+// Generate cells grouped in large blobs of the same color: random seed cells are picked and each cell takes the
+// color of its closest seed (Manhattan distance).
+func generateBlobCells(nbRows, nbCols, nbColors int, random *rand.Rand) []int {
+	// Pick the seeds, around one for 10 cells.
+	type seed struct{ row, col, color int }
+	seeds := make([]seed, 1+(nbRows*nbCols)/10)
+	for i := range seeds {
+		seeds[i] = seed{row: random.Intn(nbRows), col: random.Intn(nbCols), color: random.Intn(nbColors)}
+	}
+
+	// Assign each cell to its closest seed.
+	abs := func(x int) int {
+		if x < 0 {
+			return -x
+		}
+		return x
+	}
+	cells := make([]int, nbRows*nbCols)
+	for cellId := range cells {
+		row := cellId / nbCols
+		col := cellId % nbCols
+		bestDistance := -1
+		for _, s := range seeds {
+			distance := abs(s.row-row) + abs(s.col-col)
+			if bestDistance == -1 || distance < bestDistance {
+				bestDistance = distance
+				cells[cellId] = s.color
+			}
+		}
+	}
+	return cells
+}
+
+// Generate horizontal or vertical stripes of random width (1 to 3 cells), two consecutive stripes having different
+// colors.
+func generateStripeCells(nbRows, nbCols, nbColors int, random *rand.Rand) []int {
+	horizontal := random.Intn(2) == 0
+	length := nbCols
+	if horizontal {
+		length = nbRows
+	}
+
+	// Compute the color of each stripe line.
+	lineColors := make([]int, length)
+	color := random.Intn(nbColors)
+	for line := 0; line < length; {
+		for width := 1 + random.Intn(3); width > 0 && line < length; width-- {
+			lineColors[line] = color
+			line++
+		}
+		if nbColors > 1 {
+			color = (color + 1 + random.Intn(nbColors-1)) % nbColors
+		}
+	}
+
+	cells := make([]int, nbRows*nbCols)
+	for cellId := range cells {
+		if horizontal {
+			cells[cellId] = lineColors[cellId/nbCols]
+		} else {
+			cells[cellId] = lineColors[cellId%nbCols]
+		}
+	}
+	return cells
+}
+
+// Generate a checkerboard pattern, i.e. no two adjacent cells have the same color: the colors are cycling along the
+// diagonals, starting from a random offset.
+func generateCheckerboardCells(nbRows, nbCols, nbColors int, random *rand.Rand) []int {
+	offset := random.Intn(nbColors)
+	cells := make([]int, nbRows*nbCols)
+	for cellId := range cells {
+		cells[cellId] = (cellId/nbCols + cellId%nbCols + offset) % nbColors
+	}
+	return cells
+}
+
+// Generate an adversarial maze-like layout: a random maze is dug from the top-left cell, the corridors cycling through
+// the colors with the distance from the top-left cell. The walls have the checkerboard pattern colors, so that there
+// is no large area to merge at once and the completed area mostly progresses one cell per step.
+func generateMazeCells(nbRows, nbCols, nbColors int, random *rand.Rand) []int {
+	// Initialize all the cells as walls.
+	cells := generateCheckerboardCells(nbRows, nbCols, nbColors, random)
+	if nbColors < 2 {
+		return cells
+	}
+	dug := newBitset(nbRows * nbCols)
+
+	// Dig the maze with a randomized depth-first search moving by 2 cells, so that the corridors are separated by
+	// walls. The color of a corridor cell depends on its distance from the top-left cell.
+	type position struct{ cellId, distance int }
+	dig := func(cellId, distance int) {
+		dug.add(cellId)
+		cells[cellId] = distance % nbColors
+	}
+	dig(0, 0)
+	stack := []position{{cellId: 0, distance: 0}}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		row := current.cellId / nbCols
+		col := current.cellId % nbCols
+
+		// Get the not yet dug cells at 2 cells distance.
+		var moves [][2]int
+		for _, move := range [][2]int{{-2, 0}, {2, 0}, {0, -2}, {0, 2}} {
+			targetRow := row + move[0]
+			targetCol := col + move[1]
+			if targetRow >= 0 && targetRow < nbRows && targetCol >= 0 && targetCol < nbCols &&
+				!dug.contains(targetRow*nbCols+targetCol) {
+				moves = append(moves, move)
+			}
+		}
+		if len(moves) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		// Dig toward one of them randomly.
+		move := moves[random.Intn(len(moves))]
+		middleCellId := (row+move[0]/2)*nbCols + col + move[1]/2
+		targetCellId := (row+move[0])*nbCols + col + move[1]
+		dig(middleCellId, current.distance+1)
+		dig(targetCellId, current.distance+2)
+		stack = append(stack, position{cellId: targetCellId, distance: current.distance + 2})
+	}
+
+	return cells
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenerateBoard(t *testing.T) {
+	for pattern := range boardGenerators {
+		board, err := generateBoard(pattern, 15, 20, 4, 42)
+		if err != nil {
+			t.Fatalf("%s: %v", pattern, err)
+		}
+
+		// Check the board size and colors.
+		if board.nbRows != 15 || board.nbCols != 20 || len(board.cells) != 15*20 || board.nbColors > 4 {
+			t.Fatalf("%s: invalid board size", pattern)
+		}
+
+		// Check that the generation is deterministic.
+		other, _ := generateBoard(pattern, 15, 20, 4, 42)
+		if !reflect.DeepEqual(board.cells, other.cells) {
+			t.Fatalf("%s: the same seed should generate the same board", pattern)
+		}
+	}
+}
+
+func TestGenerateBoardErrors(t *testing.T) {
+	for _, size := range [][3]int{{0, 2, 2}, {2, 2, 0}, {2, 2, 5}, {2, 2, 1_000_000_000}} {
+		if _, err := generateBoard("random", size[0], size[1], size[2], 42); err == nil {
+			t.Fatalf("the board size should be rejected, rows=%d, cols=%d, colors=%d", size[0], size[1], size[2])
+		}
+	}
+	if _, err := generateBoard("unknown", 2, 2, 2, 42); err == nil {
+		t.Fatal("the unknown generator should be rejected")
+	}
+}
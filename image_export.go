@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Default palette mapping the board colors to RGB, the same colors as the ANSI rendering.
+var defaultImagePalette = color.Palette{
+	color.RGBA{R: 0xd7, A: 0xff},
+	color.RGBA{G: 0xaf, A: 0xff},
+	color.RGBA{G: 0x5f, B: 0xff, A: 0xff},
+	color.RGBA{R: 0xff, G: 0xd7, A: 0xff},
+	color.RGBA{R: 0xaf, B: 0xff, A: 0xff},
+	color.RGBA{G: 0xaf, B: 0xaf, A: 0xff},
+	color.RGBA{R: 0xff, G: 0x87, A: 0xff},
+	color.RGBA{R: 0xbc, G: 0xbc, B: 0xbc, A: 0xff},
+	color.RGBA{R: 0x87, G: 0x5f, A: 0xff},
+	color.RGBA{R: 0xff, G: 0x5f, B: 0xaf, A: 0xff},
+}
+
+// Parse a palette specified as comma separated RGB hexadecimal colors, e.g. "#ff0000,00ff00,0000ff".
+// The default palette is returned if the specification is empty.
+func parseImagePalette(spec string) (color.Palette, error) {
+	if spec == "" {
+		return defaultImagePalette, nil
+	}
+
+	var palette color.Palette
+	for _, hexColor := range strings.Split(spec, ",") {
+		c, err := parseHexColor(strings.TrimSpace(hexColor))
+		if err != nil {
+			return nil, err
+		}
+		palette = append(palette, c)
+	}
+	return palette, nil
+}
+
+// Returns the board drawn as an image using the palette colors, each cell being a square of cellSize pixels.
+// The palette must contain at least one color per board color.
+func renderBoardImage(board *Board, palette color.Palette, cellSize int) *image.Paletted {
+	img := image.NewPaletted(image.Rect(0, 0, board.nbCols*cellSize, board.nbRows*cellSize), palette)
+	for cellId, cellColor := range board.cells {
+		row := cellId / board.nbCols
+		col := cellId % board.nbCols
+		for y := row * cellSize; y < (row+1)*cellSize; y++ {
+			for x := col * cellSize; x < (col+1)*cellSize; x++ {
+				img.SetColorIndex(x, y, uint8(cellColor))
+			}
+		}
+	}
+	return img
+}
+
+// Replay a solution on a board and return the images of the initial board and of the board after each step.
+func renderSolutionImages(board *Board, solution []int, palette color.Palette, cellSize int) ([]*image.Paletted, error) {
+	if len(palette) < board.nbColors {
+		return nil, fmt.Errorf("the palette contains %d colors, the board requires %d", len(palette), board.nbColors)
+	}
+	if len(palette) > 256 {
+		return nil, fmt.Errorf("the palette contains %d colors, at most 256 are supported", len(palette))
+	}
+
+	board = board.clone()
+	images := []*image.Paletted{renderBoardImage(board, palette, cellSize)}
+	for i, color := range solution {
+		if color < 0 || color >= board.nbColors {
+			return nil, fmt.Errorf("invalid color %d at step #%d", color, i+1)
+		}
+		board.playStep(color)
+		images = append(images, renderBoardImage(board, palette, cellSize))
+	}
+	return images, nil
+}
+
+// Write the images as PNG files in a directory, named by step number, e.g. "step-000.png" for the initial board.
+func writeStepPngFiles(dirPath string, images []*image.Paletted) error {
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return err
+	}
+
+	for step, img := range images {
+		filePath := filepath.Join(dirPath, fmt.Sprintf("step-%03d.png", step))
+		file, err := os.Create(filePath)
+		if err != nil {
+			return err
+		}
+		err = png.Encode(file, img)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("unable to write %s: %w", filePath, err)
+		}
+	}
+	return nil
+}
+
+// Write the images as an animated GIF looping forever, each frame being displayed during the delay in hundredths of
+// a second. The last frame, i.e. the solved board, is displayed 4 times longer.
+func writeAnimatedGif(writer io.Writer, images []*image.Paletted, delay int) error {
+	animation := &gif.GIF{Image: images, Delay: make([]int, len(images))}
+	for i := range animation.Delay {
+		animation.Delay[i] = delay
+	}
+	animation.Delay[len(images)-1] = 4 * delay
+	return gif.EncodeAll(writer, animation)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+func TestParseImagePalette(t *testing.T) {
+	palette, err := parseImagePalette("#ff0000, 00ff80")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(palette) != 2 || palette[1] != (color.RGBA{G: 0xff, B: 0x80, A: 0xff}) {
+		t.Fatalf("unexpected palette %v", palette)
+	}
+
+	if _, err := parseImagePalette("ff0000,red"); err == nil {
+		t.Fatal("an invalid color should be rejected")
+	}
+}
+
+func TestRenderSolutionImages(t *testing.T) {
+	// 2x2 board solved in 2 steps.
+	board := NewBoard(2, 2, []int{0, 1, 1, 2})
+	images, err := renderSolutionImages(board, []int{1, 2}, defaultImagePalette, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 3 {
+		t.Fatalf("expected 3 images, got %d", len(images))
+	}
+
+	// Check the top-left cell color at each step, and that the board has not been modified.
+	for step, expectedColor := range []uint8{0, 1, 2} {
+		if images[step].ColorIndexAt(2, 2) != expectedColor {
+			t.Fatalf("unexpected top-left cell color at step %d", step)
+		}
+	}
+	if images[1].Bounds().Dx() != 6 || board.cells[0] != 0 {
+		t.Fatal("unexpected image size or modified board")
+	}
+
+	// Check the animated GIF.
+	var buffer bytes.Buffer
+	if err := writeAnimatedGif(&buffer, images, 10); err != nil {
+		t.Fatal(err)
+	}
+	animation, err := gif.DecodeAll(&buffer)
+	if err != nil || len(animation.Image) != 3 {
+		t.Fatalf("invalid animated GIF: %v", err)
+	}
+}
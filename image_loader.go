@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Minimum squared RGB distance between two adjacent pixels for them to be considered as an edge between two cells.
+const imageEdgeMinDistance = 3 * 32 * 32
+
+// Maximum squared RGB distance between a cell color and the color of a palette entry for the cell to be assigned to
+// the entry, larger distances create a new palette entry.
+const imagePaletteMaxDistance = 3 * 40 * 40
+
+// Load a board from a PNG, GIF or JPEG image of a grid of cells, the image being cropped to the grid.
+// The number of rows and columns is detected from the color edges of the image if not specified (0). The color of
+// each cell is sampled around its center, then the colors are clustered into a palette whose indexes are the board
+// colors, ordered by first appearance from the top-left cell.
+func parseImageBoard(reader io.Reader, nbRows, nbCols int) (*Board, error) {
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the input image: %w", err)
+	}
+	bounds := img.Bounds()
+	width := bounds.Dx()
+	height := bounds.Dy()
+	if width == 0 || height == 0 {
+		return nil, fmt.Errorf("invalid empty image")
+	}
+
+	// Closure function returning the squared RGB distance between two pixels.
+	pixelDistance := func(x1, y1, x2, y2 int) int {
+		return colorDistance(img.At(bounds.Min.X+x1, bounds.Min.Y+y1), img.At(bounds.Min.X+x2, bounds.Min.Y+y2))
+	}
+
+	// Detect the grid size.
+	if nbCols <= 0 {
+		nbCols = detectGridSize(width, height, func(position, line int) bool {
+			return pixelDistance(position-1, line, position, line) >= imageEdgeMinDistance
+		})
+	}
+	if nbRows <= 0 {
+		nbRows = detectGridSize(height, width, func(position, line int) bool {
+			return pixelDistance(line, position-1, line, position) >= imageEdgeMinDistance
+		})
+	}
+	if nbCols > width || nbRows > height {
+		return nil, fmt.Errorf("invalid grid size, rows=%d, cols=%d, for an image of %dx%d pixels", nbRows, nbCols, width, height)
+	}
+
+	// Sample the color of each cell by averaging the central half of the cell, to be robust to the compression
+	// artifacts and to the grid lines.
+	cellColors := make([]color.RGBA, nbRows*nbCols)
+	for cellId := range cellColors {
+		row := cellId / nbCols
+		col := cellId % nbCols
+		minX, maxX := col*width/nbCols, (col+1)*width/nbCols
+		minY, maxY := row*height/nbRows, (row+1)*height/nbRows
+		marginX := (maxX - minX) / 4
+		marginY := (maxY - minY) / 4
+
+		var r, g, b, count uint32
+		for y := minY + marginY; y < maxY-marginY; y++ {
+			for x := minX + marginX; x < maxX-marginX; x++ {
+				pixelR, pixelG, pixelB, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+				r += pixelR >> 8
+				g += pixelG >> 8
+				b += pixelB >> 8
+				count++
+			}
+		}
+		cellColors[cellId] = color.RGBA{R: uint8(r / count), G: uint8(g / count), B: uint8(b / count), A: 0xff}
+	}
+
+	// Cluster the cell colors into the palette.
+	cells, palette := clusterColors(cellColors)
+	board := NewBoard(nbRows, nbCols, cells)
+	board.palette = palette
+	return board, nil
+}
+
+// Detect the number of cells along an axis of an image, given the function checking whether there is an edge
+// between the pixel at a position along the axis and the previous one, for a line of pixels across the axis.
+// The positions having edges on a significant part of the lines are the cell boundaries, the cell count is the
+// smallest one whose cell boundaries match all of them.
+func detectGridSize(length, lineCount int, isEdge func(position, line int) bool) int {
+	// Count the edges at each position.
+	edgeCounts := make([]int, length)
+	for position := 1; position < length; position++ {
+		for line := 0; line < lineCount; line++ {
+			if isEdge(position, line) {
+				edgeCounts[position]++
+			}
+		}
+	}
+
+	// Get the cell boundaries, only keeping the position with the most edges when they are blurred over a few pixels,
+	// e.g. by the JPEG compression.
+	var boundaries []int
+	for position := 1; position < length; position++ {
+		if edgeCounts[position]*10 < lineCount {
+			continue
+		}
+		isMaximum := true
+		for neighbor := position - 2; neighbor <= position+2 && isMaximum; neighbor++ {
+			if neighbor >= 0 && neighbor < length && neighbor != position {
+				isMaximum = edgeCounts[neighbor] < edgeCounts[position] ||
+					(edgeCounts[neighbor] == edgeCounts[position] && neighbor > position)
+			}
+		}
+		if isMaximum {
+			boundaries = append(boundaries, position)
+		}
+	}
+
+	// Find the smallest cell count matching them, with a tolerance of 10% of the cell size for the rounding and the
+	// grid lines.
+	for cellCount := 1; cellCount < length; cellCount++ {
+		cellSize := float64(length) / float64(cellCount)
+		tolerance := math.Max(1.5, cellSize/10)
+		matching := true
+		for _, boundary := range boundaries {
+			offset := math.Mod(float64(boundary), cellSize)
+			if offset > tolerance && cellSize-offset > tolerance {
+				matching = false
+				break
+			}
+		}
+		if matching {
+			return cellCount
+		}
+	}
+	return length
+}
+
+// Cluster colors into a palette: each color is assigned to the first palette entry close enough, or to a new entry
+// otherwise. The entries are the average of their colors. Returns the palette index of each color and the palette.
+func clusterColors(colors []color.RGBA) ([]int, color.Palette) {
+	type cluster struct {
+		r, g, b, count int
+	}
+	var clusters []*cluster
+	indexes := make([]int, len(colors))
+	for i, c := range colors {
+		indexes[i] = -1
+		for clusterIdx, cl := range clusters {
+			center := color.RGBA{R: uint8(cl.r / cl.count), G: uint8(cl.g / cl.count), B: uint8(cl.b / cl.count), A: 0xff}
+			if colorDistance(c, center) <= imagePaletteMaxDistance {
+				indexes[i] = clusterIdx
+				break
+			}
+		}
+		if indexes[i] == -1 {
+			indexes[i] = len(clusters)
+			clusters = append(clusters, &cluster{})
+		}
+
+		cl := clusters[indexes[i]]
+		cl.r += int(c.R)
+		cl.g += int(c.G)
+		cl.b += int(c.B)
+		cl.count++
+	}
+
+	palette := make(color.Palette, len(clusters))
+	for i, cl := range clusters {
+		palette[i] = color.RGBA{R: uint8(cl.r / cl.count), G: uint8(cl.g / cl.count), B: uint8(cl.b / cl.count), A: 0xff}
+	}
+	return indexes, palette
+}
+
+// Returns the squared RGB distance between two colors, the components being in [0, 255].
+func colorDistance(c1, c2 color.Color) int {
+	r1, g1, b1, _ := c1.RGBA()
+	r2, g2, b2, _ := c2.RGBA()
+	dr := int(r1>>8) - int(r2>>8)
+	dg := int(g1>>8) - int(g2>>8)
+	db := int(b1>>8) - int(b2>>8)
+	return dr*dr + dg*dg + db*db
+}
+
+// Parse a color formatted as an RGB hexadecimal string, with or without the leading '#', e.g. "#ff0000".
+func parseHexColor(hexColor string) (color.RGBA, error) {
+	hexDigits := strings.TrimPrefix(hexColor, "#")
+	rgb, err := strconv.ParseUint(hexDigits, 16, 32)
+	if err != nil || len(hexDigits) != 6 {
+		return color.RGBA{}, fmt.Errorf("invalid color %q, expected format is RRGGBB", hexColor)
+	}
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}, nil
+}
+
+// Returns a color formatted as an RGB hexadecimal string, e.g. "#ff0000".
+func formatHexColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
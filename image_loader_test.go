@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestParseImageBoard(t *testing.T) {
+	board, err := readInputFile("samples/15_15_6-1.csv", &InputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := renderBoardImage(board, defaultImagePalette, 13)
+
+	// Encode the board image as PNG and as JPEG, the latter adding compression artifacts.
+	var pngBuffer, jpegBuffer bytes.Buffer
+	if err := png.Encode(&pngBuffer, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := jpeg.Encode(&jpegBuffer, img, &jpeg.Options{Quality: 75}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, buffer := range map[string]*bytes.Buffer{"png": &pngBuffer, "jpeg": &jpegBuffer} {
+		loaded, err := parseImageBoard(buffer, 0, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if loaded.nbRows != board.nbRows || loaded.nbCols != board.nbCols || len(loaded.palette) != board.nbColors {
+			t.Fatalf("%s: unexpected board size %dx%d with %d colors", name, loaded.nbRows, loaded.nbCols, len(loaded.palette))
+		}
+
+		// The colors are ordered by first appearance, check that they map one to one to the original ones.
+		mapping := make(map[int]int)
+		for cellId, color := range loaded.cells {
+			if original, exists := mapping[color]; exists && original != board.cells[cellId] {
+				t.Fatalf("%s: invalid color for cell %d", name, cellId)
+			}
+			mapping[color] = board.cells[cellId]
+		}
+	}
+}
+
+func TestDetectGridSize(t *testing.T) {
+	// 7 cells of 10.5 pixels, i.e. with boundaries rounded to the nearest pixel, and one boundary without edges.
+	boundaries := map[int]bool{11: true, 21: true, 42: true, 53: true, 63: true}
+	size := detectGridSize(74, 10, func(position, line int) bool {
+		return boundaries[position]
+	})
+	if size != 7 {
+		t.Fatalf("expected 7 cells, got %d", size)
+	}
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+)
+
+// BoardJson is the JSON representation of a board, its fields are exported to be serialized.
+type BoardJson struct {
+	// Optional name of the board.
+	Name string `json:"name,omitempty"`
+
+	// Size of the board and color of the cells, one array per row.
+	Rows  int     `json:"rows"`
+	Cols  int     `json:"cols"`
+	Cells [][]int `json:"cells"`
+
+	// Optional actual colors of the board colors, as RGB hexadecimal strings, e.g. "#ff0000".
+	Palette []string `json:"palette,omitempty"`
+
+	// Optional step count of the optimal solution.
+	KnownOptimum int `json:"known-optimum,omitempty"`
+}
+
+// SolverResult is the JSON representation of the result of an implementation execution, its fields are exported to
+// be serialized.
+type SolverResult struct {
+	// Name of the board, if known, and of the implementation.
+	Board          string `json:"board,omitempty"`
+	Implementation string `json:"implementation"`
+
+	// The best solution found and its step count.
+	Steps     []int `json:"steps"`
+	StepCount int   `json:"step-count"`
+
+	// The execution wall time and whether the solution has been proven optimal.
+	ElapsedMs     int64 `json:"elapsed-ms"`
+	OptimalProven bool  `json:"optimal-proven"`
+
+	// Statistics of the execution, see SolverStats.
+	Stats SolverResultStats `json:"stats"`
+}
+
+// SolverResultStats is the JSON representation of the statistics of an implementation execution.
+type SolverResultStats struct {
+	Evaluations int64 `json:"evaluations"`
+}
+
+// Load a board from JSON content, see BoardJson.
+func parseJsonBoard(reader io.Reader) (*Board, error) {
+	var boardJson BoardJson
+	if err := json.NewDecoder(reader).Decode(&boardJson); err != nil {
+		return nil, fmt.Errorf("unable to parse the input JSON file: %w", err)
+	}
+
+	// Check the size and the colors.
+	if boardJson.Rows <= 0 || boardJson.Cols <= 0 {
+		return nil, fmt.Errorf("invalid empty board")
+	}
+	if len(boardJson.Cells) != boardJson.Rows {
+		return nil, fmt.Errorf("invalid row count, expected=%d, actual=%d", boardJson.Rows, len(boardJson.Cells))
+	}
+	for iRow, columns := range boardJson.Cells {
+		if len(columns) != boardJson.Cols {
+			return nil, fmt.Errorf("invalid column count for row=%d, expected=%d, actual=%d", iRow+1, boardJson.Cols, len(columns))
+		}
+	}
+
+	// The size is now bounded by the actual cell count.
+	nbCells := boardJson.Rows * boardJson.Cols
+	cells := make([]int, 0, nbCells)
+	for iRow, columns := range boardJson.Cells {
+		for iCol, color := range columns {
+			if color < 0 {
+				return nil, fmt.Errorf("invalid negative color for row=%d, col=%d, color=%d", iRow+1, iCol+1, color)
+			}
+			if color >= nbCells {
+				return nil, fmt.Errorf("invalid color for row=%d, col=%d, color=%d, it must be lower than the cell count", iRow+1, iCol+1, color)
+			}
+			cells = append(cells, color)
+		}
+	}
+
+	board := NewBoard(boardJson.Rows, boardJson.Cols, cells)
+	board.name = boardJson.Name
+	board.knownOptimum = boardJson.KnownOptimum
+
+	// Parse the palette.
+	if boardJson.Palette != nil {
+		if len(boardJson.Palette) < board.nbColors {
+			return nil, fmt.Errorf("the palette contains %d colors, the board requires %d", len(boardJson.Palette), board.nbColors)
+		}
+		board.palette = make(color.Palette, len(boardJson.Palette))
+		for i, hexColor := range boardJson.Palette {
+			c, err := parseHexColor(hexColor)
+			if err != nil {
+				return nil, err
+			}
+			board.palette[i] = c
+		}
+	}
+
+	return board, nil
+}
+
+// Serialize a board to its JSON string representation, see BoardJson.
+func serializeBoardToJson(board *Board) (string, error) {
+	boardJson := BoardJson{
+		Name:         board.name,
+		Rows:         board.nbRows,
+		Cols:         board.nbCols,
+		Cells:        make([][]int, board.nbRows),
+		KnownOptimum: board.knownOptimum,
+	}
+	for iRow := range boardJson.Cells {
+		boardJson.Cells[iRow] = board.cells[iRow*board.nbCols : (iRow+1)*board.nbCols]
+	}
+	for _, c := range board.palette {
+		boardJson.Palette = append(boardJson.Palette, formatHexColor(c))
+	}
+
+	boardBytes, err := json.Marshal(boardJson)
+	if err != nil {
+		return "", fmt.Errorf("unable to serialize the board as JSON: %w", err)
+	}
+	return string(boardBytes) + "\n", nil
+}
+
+// Load the steps of a solution from a JSON result, see SolverResult.
+func parseJsonSolution(reader io.Reader) ([]int, error) {
+	var result SolverResult
+	if err := json.NewDecoder(reader).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unable to parse the solution JSON file: %w", err)
+	}
+	if result.Steps == nil {
+		return nil, fmt.Errorf("invalid solution JSON file, the steps are missing")
+	}
+	return result.Steps, nil
+}
+
+// Write a result to a JSON file, see SolverResult.
+func writeResultFile(fileName string, result *SolverResult) error {
+	resultBytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("unable to serialize the result as JSON: %w", err)
+	}
+	if err := os.WriteFile(fileName, append(resultBytes, '\n'), 0644); err != nil {
+		return fmt.Errorf("unable to write the output file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJsonBoardRoundTrip(t *testing.T) {
+	board, err := parseJsonBoard(strings.NewReader(`{
+		"name": "sample",
+		"rows": 2,
+		"cols": 3,
+		"cells": [[0, 1, 2], [2, 1, 0]],
+		"palette": ["#ff0000", "00ff00", "#0000FF"],
+		"known-optimum": 3
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if board.nbRows != 2 || board.nbCols != 3 || board.name != "sample" || board.knownOptimum != 3 {
+		t.Fatalf("unexpected board %+v", board)
+	}
+	if formatHexColor(board.palette[2]) != "#0000ff" {
+		t.Fatalf("unexpected palette %v", board.palette)
+	}
+
+	// Serialize and parse it again.
+	boardJson, err := serializeBoardToJson(board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := parseJsonBoard(strings.NewReader(boardJson))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(board.cells, other.cells) || !reflect.DeepEqual(board.palette, other.palette) ||
+		other.name != board.name || other.knownOptimum != board.knownOptimum {
+		t.Fatalf("the board has changed after a round trip: %s", boardJson)
+	}
+}
+
+func TestParseJsonBoardErrors(t *testing.T) {
+	for _, boardJson := range []string{
+		`{"rows": 0, "cols": 0, "cells": []}`,
+		`{"rows": 2, "cols": 2, "cells": [[0, 1]]}`,
+		`{"rows": 1, "cols": 2, "cells": [[0, 1, 2]]}`,
+		`{"rows": 1, "cols": 1000000000000000000, "cells": [[1]]}`,
+		`{"rows": 1000000000000000000, "cols": 1, "cells": [[1]]}`,
+		`{"rows": 1, "cols": 2, "cells": [[0, -1]]}`,
+		`{"rows": 1, "cols": 2, "cells": [[0, 999999999]]}`,
+		`{"rows": 1, "cols": 2, "cells": [[0, 1]], "palette": ["#ff0000"]}`,
+	} {
+		if _, err := parseJsonBoard(strings.NewReader(boardJson)); err == nil {
+			t.Fatalf("the board should be rejected: %s", boardJson)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"github.com/rs/zerolog/log"
+	"io"
+	"sync"
+	"time"
+)
+
+// Interval between two progress events of the machine mode.
+const machineProgressInterval = time.Second
+
+// MachineEvent is an event of the machine mode, written as a JSON line, its fields are exported to be serialized.
+// The event types are "new-best" when a better solution is found, "progress" periodically and "result" at the end of
+// the execution.
+type MachineEvent struct {
+	Event     string `json:"event"`
+	ElapsedMs int64  `json:"elapsed-ms"`
+
+	// The best solution known when the event is emitted, absent if there is none yet.
+	Steps     []int `json:"steps,omitempty"`
+	StepCount int   `json:"step-count,omitempty"`
+
+	// Statistics of the execution, see SolverStats.
+	Evaluations int64 `json:"evaluations"`
+
+	// Status of the job, only for the events streamed by the server, see SolverServer.
+	Status string `json:"status,omitempty"`
+
+	// The final result, only for the "result" event.
+	Result *SolverResult `json:"result,omitempty"`
+}
+
+// MachineEventWriter writes the machine mode events as newline-delimited JSON. The methods of a nil writer do
+// nothing, so that the machine mode can be disabled without checks.
+type MachineEventWriter struct {
+	mutex   sync.Mutex
+	encoder *json.Encoder
+	start   time.Time
+	stats   *SolverStats
+}
+
+// Returns a new event writer, the elapsed times being computed from the start time.
+func newMachineEventWriter(writer io.Writer, start time.Time, stats *SolverStats) *MachineEventWriter {
+	return &MachineEventWriter{encoder: json.NewEncoder(writer), start: start, stats: stats}
+}
+
+// Write an event, completing its elapsed time and statistics.
+func (w *MachineEventWriter) write(event *MachineEvent) {
+	if w == nil {
+		return
+	}
+	event.ElapsedMs = time.Since(w.start).Milliseconds()
+	event.Evaluations = w.stats.getEvaluationCount()
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err := w.encoder.Encode(event); err != nil {
+		log.Error().Err(err).Str("event", event.Event).Msg("unable to write the machine event")
+	}
+}
+
+// Write the event of a new best solution.
+func (w *MachineEventWriter) writeNewBest(solution []int) {
+	w.write(&MachineEvent{Event: "new-best", Steps: solution, StepCount: len(solution)})
+}
+
+// Write a progress event with the best solution known so far.
+func (w *MachineEventWriter) writeProgress(bestSolution []int) {
+	w.write(&MachineEvent{Event: "progress", Steps: bestSolution, StepCount: len(bestSolution)})
+}
+
+// Write the final result event.
+func (w *MachineEventWriter) writeResult(result *SolverResult) {
+	w.write(&MachineEvent{Event: "result", Steps: result.Steps, StepCount: result.StepCount, Result: result})
+}
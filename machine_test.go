@@ -0,0 +1,44 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMachineEventWriter(t *testing.T) {
+	// The methods of a nil writer do nothing.
+	var events *MachineEventWriter
+	events.writeNewBest([]int{0, 1})
+
+	var buffer bytes.Buffer
+	stats := &SolverStats{}
+	stats.addEvaluation()
+	events = newMachineEventWriter(&buffer, time.Now(), stats)
+	events.writeNewBest([]int{0, 1})
+	events.writeProgress(nil)
+	events.writeResult(&SolverResult{Implementation: "dummy", Steps: []int{0, 1}, StepCount: 2})
+
+	// Check that there is one JSON event per line.
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("unexpected event count %d: %s", len(lines), buffer.String())
+	}
+	for i, expected := range []string{"new-best", "progress", "result"} {
+		var event MachineEvent
+		if err := json.Unmarshal([]byte(lines[i]), &event); err != nil {
+			t.Fatal(err)
+		}
+		if event.Event != expected || event.Evaluations != 1 {
+			t.Fatalf("unexpected event %s", lines[i])
+		}
+	}
+	if strings.Contains(lines[1], "steps") {
+		t.Fatalf("the progress event should not contain steps without solution: %s", lines[1])
+	}
+	if !strings.Contains(lines[2], `"result":{"implementation":"dummy"`) {
+		t.Fatalf("unexpected result event %s", lines[2])
+	}
+}
@@ -1,104 +1,331 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 )
 
+// Maximum duration to wait for the algorithm execution to stop once the timeout is reached or a signal is received.
+const solverStopTimeout = 5 * time.Second
+
 // Available algorithm implementations.
 var implementations = map[string]AlgorithmFn{
-	"dummy":         dummy,
-	"max-area":      maximizeStepArea,
-	"max-area-deep": maximizeStepAreaDeep,
-	"deep-search":   deepSearch,
+	"dummy":                dummy,
+	"max-area":             maximizeStepArea,
+	"max-area-deep":        maximizeStepAreaDeep,
+	"deep-search":          deepSearch,
+	"parallel-deep-search": parallelDeepSearch,
+	"astar":                aStar,
+	"ida-star":             idaStar,
+	"beam":                 beamSearch,
+	"mcts":                 monteCarloTreeSearch,
+	"suffix-improve":       suffixImprove,
+}
+
+// CommandFn is the function type of the commands, it returns the process exit code.
+type CommandFn func(args []string) int
+
+// Available commands, specified as the first command line argument. Without a command, the board is solved.
+var commands = map[string]CommandFn{
+	"verify":   verifyCommand,
+	"generate": generateCommand,
+	"bench":    benchCommand,
+	"play":     playCommand,
+	"export":   exportCommand,
+	"serve":    serveCommand,
 }
 
 func main() {
+	// Execute the command if one is specified.
+	if len(os.Args) > 1 {
+		if commandFn, exists := commands[os.Args[1]]; exists {
+			os.Exit(commandFn(os.Args[2:]))
+		}
+	}
+
 	// Parse the command line arguments.
 	debug := flag.Bool("debug", false, "Enable the debug logs")
 	impl := flag.String("impl", "deep-search", "Name of the algorithm implementation to execute")
-	checkSquare := flag.Bool("check-square", true, "Check whether the board is a square after loading it")
+	inputOptions := addInputFlags(flag.CommandLine)
 	timeoutSec := flag.Int("timeout", 115, "Timeout in seconds of the execution")
 	outputFile := flag.String("output", "", "File path in which to write the solution found")
+	outputFormat := flag.String("output-format", "", "Format of the output file: csv or json, detected from the file extension by default")
+	beamWidth := flag.Int("beam-width", defaultBeamWidth, "Initial number of board configurations kept at each step by the beam search")
+	beamEvaluation := flag.String("beam-eval", "area", "Board evaluation function used by the beam search: area, frontier or colors")
+	seed := flag.Int64("seed", 0, "Seed of the random number generator used by the Monte Carlo Tree Search, 0 means a time based seed")
+	cacheMb := flag.Int("cache-mb", 0, "Size in megabytes of the transposition tables used by the deep search implementations, 0 means a size depending on the board size")
+	cacheVerify := flag.Bool("cache-verify", false, "Verify the board configuration of the cache entries to detect the hash collisions")
+	workers := flag.Int("workers", 0, "Number of workers used by the parallel implementations, 0 means one per CPU core")
+	render := flag.String("render", "csv", "Rendering of the boards in the debug trace of the linear implementations: ansi, csv or none")
+	checkpointFile := flag.String("checkpoint", "", "File in which the deep search periodically saves its state, to be resumed with -resume")
+	checkpointIntervalSec := flag.Int("checkpoint-interval", int(defaultCheckpointInterval.Seconds()), "Interval in seconds between two checkpoints of the deep search")
+	checkpointCache := flag.Bool("checkpoint-cache", false, "Save the transposition table along the checkpoints of the deep search")
+	resume := flag.Bool("resume", false, "Resume the deep search from the -checkpoint file, if it exists")
+	dbDir := flag.String("db", "", "Directory of the solution database storing the best known solution of each board, empty to disable it")
+	machine := flag.Bool("machine", false, "Write the new best solutions, the progress and the result as JSON lines on stdout instead of the plain steps")
 	flag.Parse()
 
 	inputFile := flag.Arg(0)
 
-	// Configure logging. Default level is info, unless the debug flag is present.
-	zerolog.SetGlobalLevel(zerolog.InfoLevel)
-	if *debug {
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	}
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	configureLogging(*debug)
 
 	// Load the board input file.
-	board, err := readInputFile(inputFile, *checkSquare)
+	board, err := readInputFile(inputFile, inputOptions)
 	if err != nil {
 		log.Fatal().
 			Err(err).
 			Str("input-file", inputFile).
-			Bool("check-square", *checkSquare).
 			Msg("unable to load the board input file")
 	}
 
-	// Get the algorithm implementation.
-	implFn, exists := implementations[*impl]
-	if !exists {
-		impls := make([]string, 0, len(implementations))
-		for i := range implementations {
-			impls = append(impls, i)
+	// Get the board renderer of the debug trace.
+	boardRenderer, err := getBoardRenderer(*render, *debug)
+	if err != nil {
+		log.Fatal().
+			Err(err).
+			Str("selected", *render).
+			Msg("invalid board renderer specified")
+	}
+
+	// Get the best known solution of the board from the solution database.
+	var db *SolutionDatabase = nil
+	var knownSolution []int = nil
+	optimalKnown := false
+	if *dbDir != "" {
+		db, err = openSolutionDatabase(*dbDir)
+		if err != nil {
+			log.Fatal().Err(err).Str("db", *dbDir).Msg("unable to open the solution database")
+		}
+		record, err := db.get(board)
+		if err != nil {
+			log.Warn().Err(err).Msg("unable to get the known solution from the solution database, it's ignored")
+		} else if record != nil {
+			log.Info().
+				Int("nb-steps", record.StepCount).
+				Bool("optimal-proven", record.OptimalProven).
+				Str("impl", record.Implementation).
+				Msg("known solution found in the solution database")
+			knownSolution = record.Steps
+			optimalKnown = record.OptimalProven
 		}
+	}
+
+	// Get the algorithm implementation.
+	if *checkpointFile != "" && *impl != "deep-search" {
+		log.Warn().Str("selected", *impl).Msg("the checkpoints are only supported by the deep-search implementation")
+	}
+	renderSpecified := false
+	flag.Visit(func(f *flag.Flag) {
+		renderSpecified = renderSpecified || f.Name == "render"
+	})
+	if renderSpecified && *impl != "dummy" && *impl != "max-area" && *impl != "max-area-deep" {
+		log.Warn().
+			Str("selected", *impl).
+			Msg("the board rendering is only supported by the linear implementations: dummy, max-area and max-area-deep")
+	}
+	stats := &SolverStats{}
+	solver, err := newSolver(*impl, &SolverConfig{
+		debug:              *debug,
+		workers:            *workers,
+		cacheVerify:        *cacheVerify,
+		cacheMb:            *cacheMb,
+		beamWidth:          *beamWidth,
+		beamEvaluation:     *beamEvaluation,
+		seed:               *seed,
+		stats:              stats,
+		boardRenderer:      boardRenderer,
+		checkpointFile:     *checkpointFile,
+		checkpointInterval: time.Duration(*checkpointIntervalSec) * time.Second,
+		checkpointCache:    *checkpointCache,
+		resume:             *resume,
+		knownSolution:      knownSolution,
+	})
+	if err != nil {
 		log.Fatal().
-			Strs("available", impls).
+			Err(err).
 			Str("selected", *impl).
 			Msg("invalid algorithm implementation specified")
 	}
 
 	// Execute it.
 	var bestSolution []int = nil
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), time.Duration(*timeoutSec)*time.Second)
+	defer cancel()
+	// A SIGINT or SIGTERM signal stops the execution like the timeout, the best solution found being still reported.
+	ctx, stopSignals := signal.NotifyContext(timeoutCtx, os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
 	solutions := make(chan []int, 100)
-	done := make(chan void)
-	timeout := time.After(time.Duration(*timeoutSec) * time.Second)
+	done := make(chan error, 1)
+	start := time.Now()
+	var events *MachineEventWriter
+	var progress <-chan time.Time
+	if *machine {
+		events = newMachineEventWriter(os.Stdout, start, stats)
+		ticker := time.NewTicker(machineProgressInterval)
+		defer ticker.Stop()
+		progress = ticker.C
+	}
+	// The solution returned by the implementation, i.e. its best one, is only read once done has been received: the
+	// solutions reported while it's stopping are dropped by the callback.
+	var returnedSolution []int = nil
 	go func() {
-		_, err := implFn(board, solutions, done, *debug)
-		if err != nil {
-			log.Fatal().Err(err).Msg("error during the algorithm execution")
+		if optimalKnown {
+			// The known solution is optimal, there is nothing to search.
+			stats.setOptimalProven()
+			solutions <- knownSolution
+			done <- nil
+			return
 		}
+		solution, err := solver.Solve(ctx, board, func(solution []int) {
+			// Forward the solution to the main loop, unless it has already stopped listening.
+			select {
+			case solutions <- solution:
+			case <-ctx.Done():
+			}
+		})
+		returnedSolution = solution
+		done <- err
 	}()
 
+	// Closure function processing a solution pushed to the channel.
+	processSolution := func(solution []int) {
+		if bestSolution == nil || len(solution) < len(bestSolution) {
+			log.Info().Int("nb-steps", len(solution)).Ints("solution", solution).Msg("new best solution found")
+			bestSolution = solution
+			events.writeNewBest(solution)
+		}
+	}
+
 mainLoop:
 	for {
 		select {
 		case solution := <-solutions:
 			// A new solution has been pushed to the channel.
-			if bestSolution == nil || len(solution) < len(bestSolution) {
-				log.Info().Int("nb-steps", len(solution)).Ints("solution", solution).Msg("new best solution found")
-				bestSolution = solution
-			}
-		case <-done:
+			processSolution(solution)
+		case <-progress:
+			// Report the progress in machine mode.
+			events.writeProgress(bestSolution)
+		case err := <-done:
 			// The algorithm execution is finished.
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled) {
+				log.Fatal().Err(err).Msg("error during the algorithm execution")
+			}
 			log.Info().Msg("algorithm execution finished")
+
+			// Process the solutions remaining in the channel and the returned one.
+			for len(solutions) > 0 {
+				processSolution(<-solutions)
+			}
+			if returnedSolution != nil {
+				processSolution(returnedSolution)
+			}
 			break mainLoop
-		case <-timeout:
-			// Timeout, the algorithm execution must be stopped.
-			log.Warn().Msg("timeout reached during the algorithm execution")
+		case <-ctx.Done():
+			// Timeout or signal, the algorithm execution must be stopped.
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				log.Warn().Msg("timeout reached during the algorithm execution")
+			} else {
+				log.Warn().Msg("signal received during the algorithm execution")
+			}
+			// Restore the default behavior, so that a second signal kills the process.
+			stopSignals()
+
+			// Wait for the algorithm execution to stop, leaving time to the deep search to save its last checkpoint.
+			stopTimeout := solverStopTimeout
+			if *checkpointFile != "" {
+				stopTimeout = checkpointSaveTimeout
+			}
+			stopped := false
+			select {
+			case <-done:
+				stopped = true
+			case <-time.After(stopTimeout):
+				log.Warn().Msg("timeout reached while waiting for the algorithm execution to stop")
+			}
+
+			// Process the solutions remaining in the channel and the returned one if the execution has stopped.
+			for len(solutions) > 0 {
+				processSolution(<-solutions)
+			}
+			if stopped && returnedSolution != nil {
+				processSolution(returnedSolution)
+			}
 			break mainLoop
 		}
 	}
 
 	// Print the best solution found.
+	elapsed := time.Since(start)
+	log.Info().
+		Dur("elapsed", elapsed).
+		Int64("evaluations", stats.getEvaluationCount()).
+		Bool("optimal-proven", stats.isOptimalProven()).
+		Msg("search statistics")
 	log.Info().Int("nb-steps", len(bestSolution)).Ints("solution", bestSolution).Msg("best solution")
-	for _, color := range bestSolution {
-		fmt.Println(color)
+	if board.knownOptimum > 0 {
+		event := log.Info()
+		if len(bestSolution) > board.knownOptimum {
+			event = log.Warn()
+		}
+		event.Int("nb-steps", len(bestSolution)).Int("known-optimum", board.knownOptimum).Msg("comparison with the known optimum")
+	}
+	if board.palette != nil {
+		// Translate the solution to the actual colors.
+		hexColors := make([]string, len(bestSolution))
+		for i, color := range bestSolution {
+			hexColors[i] = formatHexColor(board.palette[color])
+		}
+		log.Info().Strs("colors", hexColors).Msg("best solution colors")
+	}
+	result := &SolverResult{
+		Board:          board.name,
+		Implementation: *impl,
+		Steps:          bestSolution,
+		StepCount:      len(bestSolution),
+		ElapsedMs:      elapsed.Milliseconds(),
+		OptimalProven:  stats.isOptimalProven(),
+		Stats:          SolverResultStats{Evaluations: stats.getEvaluationCount()},
+	}
+	if events != nil {
+		events.writeResult(result)
+	} else {
+		for _, color := range bestSolution {
+			fmt.Println(color)
+		}
+	}
+
+	// Store the best solution in the solution database if it improves the known one.
+	if db != nil {
+		updated, err := db.update(board, bestSolution, stats.isOptimalProven(), *impl)
+		if err != nil {
+			log.Error().Err(err).Msg("unable to update the solution database")
+		} else if updated {
+			log.Info().Int("nb-steps", len(bestSolution)).Msg("solution database updated")
+		}
 	}
 
 	// Generate the output file.
 	if *outputFile != "" {
-		err = writeOutputFile(*outputFile, bestSolution)
+		format := *outputFormat
+		if format == "" {
+			format = getFileFormat(*outputFile)
+		}
+		switch format {
+		case "json":
+			err = writeResultFile(*outputFile, result)
+		case "csv":
+			err = writeOutputFile(*outputFile, bestSolution)
+		default:
+			err = fmt.Errorf("invalid output format %q", format)
+		}
 		if err != nil {
 			log.Fatal().
 				Err(err).
@@ -106,3 +333,12 @@ mainLoop:
 		}
 	}
 }
+
+// Configure logging. Default level is info, unless the debug flag is present.
+func configureLogging(debug bool) {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	if debug {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	}
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+}
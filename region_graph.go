@@ -0,0 +1,338 @@
+package main
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// RegionGraph is the graph of the regions of a board, i.e. the maximal contiguous areas of cells with the same
+// color. Two regions are linked by an edge if they have adjacent cells.
+// Two adjacent regions always have different colors, otherwise they would be the same region.
+type RegionGraph struct {
+	// Number of rows in the board.
+	nbRows int
+
+	// Number of columns in the board.
+	nbCols int
+
+	// Number of colors in the board, the colors are in [0, nbColors).
+	nbColors int
+
+	// Slice of the region ID of each cell, indexed by the cell ID.
+	cellRegions []int
+
+	// Slice of the color of each region, indexed by the region ID.
+	regionColors []int
+
+	// Slice of the number of cells of each region, indexed by the region ID.
+	regionSizes []int
+
+	// Slice of the IDs of the regions adjacent to each region, indexed by the region ID.
+	regionNeighbors [][]int
+}
+
+// Build the region graph of a board by collapsing its cells into regions.
+// The region containing the top-left cell always has the ID 0.
+func newRegionGraph(board *Board) *RegionGraph {
+	graph := &RegionGraph{
+		nbRows:      board.nbRows,
+		nbCols:      board.nbCols,
+		nbColors:    board.nbColors,
+		cellRegions: make([]int, len(board.cells)),
+	}
+	for cellId := range graph.cellRegions {
+		graph.cellRegions[cellId] = -1
+	}
+
+	// Flood fill each cell not yet assigned to a region to identify the cells of its region.
+	var cellsToProcess []int
+	for startCellId, startColor := range board.cells {
+		if graph.cellRegions[startCellId] != -1 {
+			continue
+		}
+
+		// Create the region.
+		regionId := len(graph.regionColors)
+		graph.regionColors = append(graph.regionColors, startColor)
+		graph.regionSizes = append(graph.regionSizes, 0)
+
+		// Closure function processing one cell.
+		processCell := func(cellId int) {
+			// Check if the cell has the same color as the region and has not been already processed.
+			if board.cells[cellId] == startColor && graph.cellRegions[cellId] == -1 {
+				graph.cellRegions[cellId] = regionId
+				cellsToProcess = append(cellsToProcess, cellId)
+			}
+		}
+
+		// Loop over the stack of cells to process until it's empty.
+		processCell(startCellId)
+		for len(cellsToProcess) > 0 {
+			// Pop a cell from the stack and add it to the region.
+			cellId := cellsToProcess[len(cellsToProcess)-1]
+			cellsToProcess = cellsToProcess[:len(cellsToProcess)-1]
+			graph.regionSizes[regionId]++
+
+			graph.forEachAdjacentCell(cellId, processCell)
+		}
+	}
+
+	// Compute the edges between the regions by looking at the right and bottom adjacent cells of each cell.
+	neighborSets := make([]map[int]void, len(graph.regionColors))
+	for regionId := range neighborSets {
+		neighborSets[regionId] = make(map[int]void)
+	}
+	addEdge := func(regionId1, regionId2 int) {
+		if regionId1 != regionId2 {
+			neighborSets[regionId1][regionId2] = void{}
+			neighborSets[regionId2][regionId1] = void{}
+		}
+	}
+	for cellId, regionId := range graph.cellRegions {
+		row := cellId / graph.nbCols
+		col := cellId % graph.nbCols
+
+		// Bottom
+		if row < (graph.nbRows - 1) {
+			addEdge(regionId, graph.cellRegions[cellId+graph.nbCols])
+		}
+
+		// Right
+		if col < (graph.nbCols - 1) {
+			addEdge(regionId, graph.cellRegions[cellId+1])
+		}
+	}
+
+	// Store the neighbors as sorted slices to have a deterministic iteration order.
+	graph.regionNeighbors = make([][]int, len(neighborSets))
+	for regionId, neighborSet := range neighborSets {
+		neighbors := make([]int, 0, len(neighborSet))
+		for neighborId := range neighborSet {
+			neighbors = append(neighbors, neighborId)
+		}
+		sort.Ints(neighbors)
+		graph.regionNeighbors[regionId] = neighbors
+	}
+
+	return graph
+}
+
+// Call the specified function for the top, bottom, left and right adjacent cells of a cell.
+func (graph *RegionGraph) forEachAdjacentCell(cellId int, fn func(cellId int)) {
+	row := cellId / graph.nbCols
+	col := cellId % graph.nbCols
+
+	// Top
+	if row > 0 {
+		fn(cellId - graph.nbCols)
+	}
+
+	// Bottom
+	if row < (graph.nbRows - 1) {
+		fn(cellId + graph.nbCols)
+	}
+
+	// Left
+	if col > 0 {
+		fn(cellId - 1)
+	}
+
+	// Right
+	if col < (graph.nbCols - 1) {
+		fn(cellId + 1)
+	}
+}
+
+// Returns the number of regions in the graph.
+func (graph *RegionGraph) getRegionCount() int {
+	return len(graph.regionColors)
+}
+
+// GraphBoard represents the current status of the game using the region graph of the initial board.
+// The regions colors never change: only the color of the completed area does, and the regions are merged into it
+// when it's changed to their color. Thus, the board status is only made of the completed area color and the sets of
+// completed and frontier regions.
+type GraphBoard struct {
+	// The region graph of the initial board, shared by all the clones.
+	graph *RegionGraph
+
+	// Current color of the completed area.
+	color int
+
+	// Set of the regions that are part of the completed area.
+	completedRegions Bitset
+
+	// Number of cells in the completedRegions set.
+	completedCount int
+
+	// Set of the regions adjacent to the completed area.
+	frontierRegions Bitset
+}
+
+func NewGraphBoard(graph *RegionGraph) *GraphBoard {
+	// Create the board.
+	board := &GraphBoard{
+		graph:            graph,
+		color:            graph.regionColors[0],
+		completedRegions: newBitset(graph.getRegionCount()),
+		frontierRegions:  newBitset(graph.getRegionCount()),
+	}
+
+	// Initialize the board with a completed area consisting of only the region of the top-left cell.
+	board.completeRegion(0)
+
+	return board
+}
+
+func (board *GraphBoard) clone() *GraphBoard {
+	return &GraphBoard{
+		graph:            board.graph,
+		color:            board.color,
+		completedRegions: board.completedRegions.clone(),
+		completedCount:   board.completedCount,
+		frontierRegions:  board.frontierRegions.clone(),
+	}
+}
+
+// Integrate a region in the completed area and its adjacent regions into the frontier (if necessary).
+func (board *GraphBoard) completeRegion(regionId int) {
+	board.frontierRegions.remove(regionId)
+	board.completedRegions.add(regionId)
+	board.completedCount += board.graph.regionSizes[regionId]
+
+	for _, neighborId := range board.graph.regionNeighbors[regionId] {
+		if !board.completedRegions.contains(neighborId) {
+			board.frontierRegions.add(neighborId)
+		}
+	}
+}
+
+// Execute a step by changing the color of the completed area to the specified color, and by merging into it all the
+// frontier regions of this color.
+// As two adjacent regions never have the same color, the regions added to the frontier are never of this color and
+// a single pass on the frontier is enough.
+func (board *GraphBoard) playStep(color int) {
+	board.color = color
+
+	// Collect the frontier regions of this color before merging them, as merging modifies the frontier.
+	var regionsToComplete []int
+	board.frontierRegions.forEach(func(regionId int) {
+		if board.graph.regionColors[regionId] == color {
+			regionsToComplete = append(regionsToComplete, regionId)
+		}
+	})
+
+	for _, regionId := range regionsToComplete {
+		board.completeRegion(regionId)
+	}
+}
+
+// Return a string identifier uniquely identifying a board configuration.
+// The color of the completed area is not part of it as it has no impact on the steps remaining to be played.
+func (board *GraphBoard) getId() string {
+	id := make([]byte, 8*len(board.completedRegions))
+	for i, word := range board.completedRegions {
+		binary.LittleEndian.PutUint64(id[8*i:], word)
+	}
+	return string(id)
+}
+
+// Returns the eccentricity of the completed area, i.e. the largest distance (in edges count) between it and the
+// other regions of the graph. It's a lower bound of the number of steps needed to solve the board as a step can only
+// merge the regions adjacent to the completed area.
+func (board *GraphBoard) getEccentricity() int {
+	// Breadth first search from the completed area, the frontier regions being at distance 1.
+	regionCount := board.graph.getRegionCount()
+	visitedRegions := board.completedRegions.clone()
+	regionsToProcess := make([]int, 0, regionCount)
+	board.frontierRegions.forEach(func(regionId int) {
+		visitedRegions.add(regionId)
+		regionsToProcess = append(regionsToProcess, regionId)
+	})
+
+	eccentricity := 0
+	for len(regionsToProcess) > 0 {
+		eccentricity++
+
+		// Process the current layer of regions and compute the next one.
+		layerSize := len(regionsToProcess)
+		for _, regionId := range regionsToProcess[:layerSize] {
+			for _, neighborId := range board.graph.regionNeighbors[regionId] {
+				if !visitedRegions.contains(neighborId) {
+					visitedRegions.add(neighborId)
+					regionsToProcess = append(regionsToProcess, neighborId)
+				}
+			}
+		}
+		regionsToProcess = regionsToProcess[layerSize:]
+	}
+
+	return eccentricity
+}
+
+// Returns the color of a cell specified by its ID.
+func (board *GraphBoard) getCellColor(cellId int) int {
+	regionId := board.graph.cellRegions[cellId]
+	if board.completedRegions.contains(regionId) {
+		return board.color
+	}
+	return board.graph.regionColors[regionId]
+}
+
+// Returns the list of colors in the frontier ordered by descending area size, the ties being ordered by ascending
+// color.
+func (board *GraphBoard) getColorsInFrontier() []int {
+	// Compute the size of the areas accessible from the frontier and grouped by color.
+	areaSizeByColor := make([]int, board.graph.nbColors)
+	board.frontierRegions.forEach(func(regionId int) {
+		areaSizeByColor[board.graph.regionColors[regionId]] += board.graph.regionSizes[regionId]
+	})
+
+	// Get the list of colors.
+	colors := make([]int, 0, board.graph.nbColors)
+	for color, areaSize := range areaSizeByColor {
+		if areaSize > 0 {
+			colors = append(colors, color)
+		}
+	}
+
+	// Order it by the cell count in descending order.
+	sort.SliceStable(colors, func(i, j int) bool {
+		return areaSizeByColor[colors[i]] > areaSizeByColor[colors[j]]
+	})
+
+	return colors
+}
+
+// Returns a map of the remaining colors in the board, with the color as key and the cell count as value.
+func (board *GraphBoard) getRemainingColors() map[int]int {
+	remainingColors := make(map[int]int)
+	for regionId, color := range board.graph.regionColors {
+		if !board.completedRegions.contains(regionId) {
+			remainingColors[color] += board.graph.regionSizes[regionId]
+		}
+	}
+	return remainingColors
+}
+
+// Returns the number of remaining colors in the board, i.e. len(getRemainingColors()) without the allocation of
+// the map.
+func (board *GraphBoard) getRemainingColorCount() int {
+	remainingColors := newBitset(board.graph.nbColors)
+	count := 0
+	for regionId, color := range board.graph.regionColors {
+		if !remainingColors.contains(color) && !board.completedRegions.contains(regionId) {
+			remainingColors.add(color)
+			count++
+			if count == board.graph.nbColors {
+				break
+			}
+		}
+	}
+	return count
+}
+
+// Returns whether the board is solved, i.e. all the regions are in the completed area.
+func (board *GraphBoard) isSolved() bool {
+	return board.frontierRegions.isEmpty()
+}
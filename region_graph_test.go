@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGraphBoardMatchesBoard(t *testing.T) {
+	inputFiles, err := filepath.Glob("samples/*.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for _, inputFile := range inputFiles {
+		board, err := readInputFile(inputFile, &InputOptions{})
+		if err != nil {
+			t.Fatalf("unable to load the board input file %s: %v", inputFile, err)
+		}
+		graphBoard := NewGraphBoard(newRegionGraph(board))
+
+		// Play random colors on both boards and check that they stay in the same status.
+		for step := 0; ; step++ {
+			colors := board.getColorsInFrontier()
+			if !reflect.DeepEqual(colors, graphBoard.getColorsInFrontier()) {
+				t.Fatalf("%s, step #%d: colors in frontier differ", inputFile, step)
+			}
+			if board.completedCount != graphBoard.completedCount {
+				t.Fatalf("%s, step #%d: completed cell count differs", inputFile, step)
+			}
+			if !reflect.DeepEqual(board.getRemainingColors(), graphBoard.getRemainingColors()) {
+				t.Fatalf("%s, step #%d: remaining colors differ", inputFile, step)
+			}
+			for cellId, color := range board.cells {
+				if graphBoard.getCellColor(cellId) != color {
+					t.Fatalf("%s, step #%d: color of cell %d differs", inputFile, step, cellId)
+				}
+			}
+			if board.isSolved() != graphBoard.isSolved() {
+				t.Fatalf("%s, step #%d: solved status differs", inputFile, step)
+			}
+			if board.isSolved() {
+				break
+			}
+
+			color := colors[random.Intn(len(colors))]
+			board.playStep(color)
+			graphBoard.playStep(color)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// BoardRendererFn is the function type returning the rendering of a board, as printed in the debug trace.
+type BoardRendererFn func(board *Board) (string, error)
+
+// Available board renderers, "none" disabling the debug trace.
+var boardRenderers = map[string]BoardRendererFn{
+	"ansi": renderBoardAnsiTrace,
+	"csv":  serializeBoardToCsv,
+	"none": nil,
+}
+
+// Returns the board renderer specified by its name, used for the debug trace. No renderer is returned if the debug
+// logs are disabled, and the ANSI renderer falls back to the CSV one when stderr is not a terminal.
+func getBoardRenderer(name string, debug bool) (BoardRendererFn, error) {
+	rendererFn, exists := boardRenderers[name]
+	if !exists {
+		return nil, fmt.Errorf("invalid board renderer %q", name)
+	}
+	if !debug {
+		return nil, nil
+	}
+	if name == "ansi" && !isTerminal(os.Stderr) {
+		return serializeBoardToCsv, nil
+	}
+	return rendererFn, nil
+}
+
+// Check if a file is a terminal, i.e. a character device.
+func isTerminal(file *os.File) bool {
+	fileInfo, err := file.Stat()
+	return err == nil && fileInfo.Mode()&os.ModeCharDevice != 0
+}
+
+// ANSI escape sequences used to render the boards in a terminal.
+const (
+	ansiReset           = "\x1b[0m"
+	ansiBlackForeground = "\x1b[30m"
+	ansiClearScreen     = "\x1b[H\x1b[2J"
+)
+
+// Colors of the 256 colors ANSI palette used for the first board colors, the next ones cycle through the palette.
+var ansiPalette = []int{160, 34, 27, 220, 129, 37, 208, 250, 94, 205}
+
+// Returns the ANSI escape sequence setting the background to the specified board color.
+func ansiBackground(color int) string {
+	paletteColor := 16 + color%216
+	if color < len(ansiPalette) {
+		paletteColor = ansiPalette[color]
+	}
+	return fmt.Sprintf("\x1b[48;5;%dm", paletteColor)
+}
+
+// Returns the board rendered with ANSI background colors, each cell being 2 characters wide.
+// If requested, the cells of the completed area are marked with "::" and the ones of the frontier with "[]".
+func renderBoardAnsi(board *Board, highlight bool) string {
+	var builder strings.Builder
+	for row := 0; row < board.nbRows; row++ {
+		for col := 0; col < board.nbCols; col++ {
+			cellId := row*board.nbCols + col
+			builder.WriteString(ansiBackground(board.cells[cellId]))
+			builder.WriteString(ansiBlackForeground)
+			switch {
+			case highlight && board.completedCells.contains(cellId):
+				builder.WriteString("::")
+			case highlight && board.frontierCells.contains(cellId):
+				builder.WriteString("[]")
+			default:
+				builder.WriteString("  ")
+			}
+		}
+		builder.WriteString(ansiReset)
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// Returns the board rendered with ANSI background colors and the completed area and frontier highlighted, followed by
+// the legend of the colors.
+func renderBoardAnsiTrace(board *Board) (string, error) {
+	return renderBoardAnsi(board, true) + renderColorLegendAnsi(board.nbColors) + "\n", nil
+}
+
+// Returns the legend of the board colors, i.e. each color index rendered with its background.
+func renderColorLegendAnsi(nbColors int) string {
+	var builder strings.Builder
+	for color := 0; color < nbColors; color++ {
+		builder.WriteString(ansiBackground(color))
+		builder.WriteString(ansiBlackForeground)
+		builder.WriteString(fmt.Sprintf(" %d ", color))
+		builder.WriteString(ansiReset)
+		builder.WriteString(" ")
+	}
+	return builder.String()
+}
@@ -0,0 +1,593 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog/log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Maximum size in bytes of the board submitted to the server.
+const serverMaxBoardSize = 10 * 1024 * 1024
+
+// Number of events buffered for each client streaming the events of a job, the new events are dropped beyond.
+const jobEventBufferSize = 16
+
+// Default maximum size in megabytes of the transposition table of a job, the tables of the small boards being smaller.
+const defaultJobCacheMb = 64
+
+// Interval between two purges of the expired jobs.
+const jobPurgeInterval = time.Minute
+
+// Statuses of the solving jobs.
+const (
+	jobStatusQueued    = "queued"
+	jobStatusRunning   = "running"
+	jobStatusFinished  = "finished"
+	jobStatusCancelled = "cancelled"
+	jobStatusFailed    = "failed"
+)
+
+// Job is a board solving job of the server, executed by one of its workers.
+// It's safe for concurrent use by multiple Goroutines.
+type Job struct {
+	// ID of the job, the board to solve, the implementation to execute and its timeout.
+	id      string
+	board   *Board
+	impl    string
+	timeout time.Duration
+
+	// Context of the job, cancelled to stop it, and its cancellation function.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Statistics of the execution.
+	stats *SolverStats
+
+	// Mutable state of the job, protected by the mutex.
+	mutex        sync.Mutex
+	status       string
+	bestSolution []int
+	startTime    time.Time
+	endTime      time.Time
+	timeoutHit   bool
+	err          error
+
+	// Channels of the clients streaming the events of the job, they are closed once the job is finished.
+	subscribers map[chan *MachineEvent]struct{}
+}
+
+// JobStatus is the JSON representation of the status of a job, its fields are exported to be serialized.
+type JobStatus struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+
+	// Whether the timeout has been reached, and the error if the job has failed.
+	Timeout bool   `json:"timeout"`
+	Error   string `json:"error,omitempty"`
+
+	// The best solution found so far and the statistics of the execution.
+	Result *SolverResult `json:"result"`
+}
+
+// Callback function recording the solutions found by the implementation.
+func (job *Job) onSolution(solution []int) {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	if job.bestSolution == nil || len(solution) < len(job.bestSolution) {
+		job.bestSolution = solution
+		job.publishLocked(job.newEventLocked("new-best"))
+	}
+}
+
+// Returns a new event of the job with its current state, the mutex must be held.
+func (job *Job) newEventLocked(eventType string) *MachineEvent {
+	result := job.getResultLocked()
+	event := &MachineEvent{
+		Event:       eventType,
+		ElapsedMs:   result.ElapsedMs,
+		Steps:       result.Steps,
+		StepCount:   result.StepCount,
+		Evaluations: result.Stats.Evaluations,
+		Status:      job.status,
+	}
+	if eventType == "result" {
+		event.Result = result
+	}
+	return event
+}
+
+// Returns a new event of the job with its current state.
+func (job *Job) newEvent(eventType string) *MachineEvent {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	return job.newEventLocked(eventType)
+}
+
+// Send an event to the clients streaming the events of the job, the mutex must be held. The event is dropped for
+// the clients too slow to consume the previous ones, so that the job is never blocked.
+func (job *Job) publishLocked(event *MachineEvent) {
+	for subscriber := range job.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			log.Debug().Str("job", job.id).Str("event", event.Event).Msg("event dropped for a slow client")
+		}
+	}
+}
+
+// Returns a new channel receiving the events of the job, closed once the job is finished after the "result" event.
+// Returns nil if the job is already finished.
+func (job *Job) subscribe() chan *MachineEvent {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	if !job.endTime.IsZero() {
+		return nil
+	}
+	subscriber := make(chan *MachineEvent, jobEventBufferSize)
+	if job.subscribers == nil {
+		job.subscribers = make(map[chan *MachineEvent]struct{})
+	}
+	job.subscribers[subscriber] = struct{}{}
+	return subscriber
+}
+
+// Stop sending the events of the job to a channel returned by subscribe.
+func (job *Job) unsubscribe(subscriber chan *MachineEvent) {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	delete(job.subscribers, subscriber)
+}
+
+// Record the end of the job and send the "result" event to the clients, the mutex must be held.
+func (job *Job) finishLocked() {
+	job.endTime = time.Now()
+	event := job.newEventLocked("result")
+	for subscriber := range job.subscribers {
+		// The channel can't be full for the last event, the client would miss the result otherwise.
+		select {
+		case subscriber <- event:
+		default:
+			<-subscriber
+			subscriber <- event
+		}
+		close(subscriber)
+	}
+	job.subscribers = nil
+}
+
+// Returns whether the job is finished, whatever its outcome, for longer than the retention duration.
+func (job *Job) isExpired(retention time.Duration) bool {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	return !job.endTime.IsZero() && time.Since(job.endTime) > retention
+}
+
+// Returns the best solution found so far and the statistics of the execution, the mutex must be held.
+func (job *Job) getResultLocked() *SolverResult {
+	var elapsed time.Duration
+	if !job.startTime.IsZero() {
+		endTime := job.endTime
+		if endTime.IsZero() {
+			endTime = time.Now()
+		}
+		elapsed = endTime.Sub(job.startTime)
+	}
+	return &SolverResult{
+		Board:          job.board.name,
+		Implementation: job.impl,
+		Steps:          job.bestSolution,
+		StepCount:      len(job.bestSolution),
+		ElapsedMs:      elapsed.Milliseconds(),
+		OptimalProven:  job.stats.isOptimalProven(),
+		Stats:          SolverResultStats{Evaluations: job.stats.getEvaluationCount()},
+	}
+}
+
+// Returns the current status of the job.
+func (job *Job) getStatus() *JobStatus {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+
+	status := &JobStatus{
+		ID:      job.id,
+		Status:  job.status,
+		Timeout: job.timeoutHit,
+		Result:  job.getResultLocked(),
+	}
+	if job.err != nil {
+		status.Error = job.err.Error()
+	}
+	return status
+}
+
+// Execute the job, unless it has been cancelled while queued.
+func (job *Job) run(config *SolverConfig) {
+	job.mutex.Lock()
+	if job.ctx.Err() != nil {
+		job.mutex.Unlock()
+		return
+	}
+	job.status = jobStatusRunning
+	job.startTime = time.Now()
+	job.mutex.Unlock()
+	log.Info().Str("job", job.id).Str("impl", job.impl).Msg("job started")
+
+	// Execute the implementation with its own statistics, the size of its transposition table being bounded by the
+	// configured one so that the concurrent jobs don't exhaust the memory.
+	jobConfig := *config
+	jobConfig.stats = job.stats
+	jobConfig.cacheMb = getTranspositionTableSizeMb(0, job.board)
+	if config.cacheMb > 0 && jobConfig.cacheMb > config.cacheMb {
+		jobConfig.cacheMb = config.cacheMb
+	}
+	ctx, cancel := context.WithTimeout(job.ctx, job.timeout)
+	defer cancel()
+	solver, err := newSolver(job.impl, &jobConfig)
+	if err == nil {
+		_, err = solver.Solve(ctx, job.board, job.onSolution)
+	}
+
+	// Record the outcome.
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	switch {
+	case err == nil:
+		job.status = jobStatusFinished
+	case errors.Is(err, context.DeadlineExceeded):
+		job.status = jobStatusFinished
+		job.timeoutHit = true
+	case errors.Is(err, context.Canceled):
+		job.status = jobStatusCancelled
+	default:
+		job.status = jobStatusFailed
+		job.err = err
+	}
+	job.finishLocked()
+	log.Info().
+		Str("job", job.id).
+		Str("status", job.status).
+		Int("nb-steps", len(job.bestSolution)).
+		Int64("evaluations", job.stats.getEvaluationCount()).
+		Msg("job finished")
+}
+
+// Cancel the job, a queued job is cancelled immediately while a running one is cancelled once its implementation
+// has stopped.
+func (job *Job) stop() {
+	job.mutex.Lock()
+	defer job.mutex.Unlock()
+	job.cancel()
+	if job.status == jobStatusQueued {
+		job.status = jobStatusCancelled
+		job.finishLocked()
+	}
+}
+
+// SolverServer is the HTTP API executing board solving jobs with a bounded pool of workers:
+//   - POST /jobs submits a board, as CSV or JSON depending on the content type, the implementation and the timeout in
+//     seconds being specified by the "impl" and "timeout" query parameters; the job status is returned
+//   - GET /jobs/{id} returns the status of a job, with its best solution found so far and its statistics
+//   - DELETE /jobs/{id} cancels a job and returns its status
+//   - GET /jobs/{id}/events streams the events of a job as Server-Sent Events: "new-best" for each better solution,
+//     "progress" periodically and "result" at the end, their data being a MachineEvent
+type SolverServer struct {
+	// Configuration of the implementations, and the default and maximum timeouts of the jobs.
+	solverConfig   *SolverConfig
+	defaultTimeout time.Duration
+	maxTimeout     time.Duration
+
+	// Duration during which the finished jobs are kept.
+	retention time.Duration
+
+	// The jobs by ID and the queue of the ones waiting for a worker, in submission order, protected by the mutex.
+	// The cancelled jobs are removed from the queue, the submissions are rejected once it contains queueSize jobs.
+	// The workers wait on the condition for a job to be queued.
+	mutex     sync.Mutex
+	jobs      map[string]*Job
+	queue     []*Job
+	queueSize int
+	queueCond *sync.Cond
+
+	// Context of the server, cancelled to stop the workers and the jobs.
+	ctx context.Context
+}
+
+// Returns a new server, its workers are started and stopped once the context is done.
+func newSolverServer(ctx context.Context, solverConfig *SolverConfig, workerCount, queueSize int, defaultTimeout, maxTimeout, retention time.Duration) *SolverServer {
+	server := &SolverServer{
+		solverConfig:   solverConfig,
+		defaultTimeout: defaultTimeout,
+		maxTimeout:     maxTimeout,
+		retention:      retention,
+		jobs:           make(map[string]*Job),
+		queueSize:      queueSize,
+		ctx:            ctx,
+	}
+	server.queueCond = sync.NewCond(&server.mutex)
+	for i := 0; i < workerCount; i++ {
+		go server.runWorker()
+	}
+
+	// Periodically purge the expired jobs, and wake up the idle workers once the server is stopped.
+	go func() {
+		ticker := time.NewTicker(jobPurgeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				server.purgeJobs()
+			case <-ctx.Done():
+				server.mutex.Lock()
+				server.queueCond.Broadcast()
+				server.mutex.Unlock()
+				return
+			}
+		}
+	}()
+	return server
+}
+
+// Worker loop executing the queued jobs until the server is stopped.
+func (server *SolverServer) runWorker() {
+	for {
+		// Wait for a job to be queued.
+		server.mutex.Lock()
+		for len(server.queue) == 0 && server.ctx.Err() == nil {
+			server.queueCond.Wait()
+		}
+		if server.ctx.Err() != nil {
+			server.mutex.Unlock()
+			return
+		}
+		job := server.queue[0]
+		server.queue[0] = nil
+		server.queue = server.queue[1:]
+		server.mutex.Unlock()
+
+		job.run(server.solverConfig)
+	}
+}
+
+// Remove a job from the queue, if it's still waiting for a worker.
+func (server *SolverServer) dequeueJob(job *Job) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	for i, queuedJob := range server.queue {
+		if queuedJob == job {
+			server.queue = append(server.queue[:i], server.queue[i+1:]...)
+			return
+		}
+	}
+}
+
+// Returns the HTTP handler of the API.
+func (server *SolverServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/jobs", server.handleJobs)
+	mux.HandleFunc("/jobs/", server.handleJob)
+	return mux
+}
+
+// Handle the requests on the jobs collection.
+func (server *SolverServer) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJsonError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	job, err := server.newJob(w, r)
+	if err != nil {
+		writeJsonError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Queue the job, unless the queue is full.
+	server.mutex.Lock()
+	if len(server.queue) >= server.queueSize {
+		server.mutex.Unlock()
+		job.cancel()
+		writeJsonError(w, http.StatusServiceUnavailable, fmt.Errorf("the job queue is full"))
+		return
+	}
+	server.queue = append(server.queue, job)
+	server.jobs[job.id] = job
+	server.queueCond.Signal()
+	server.mutex.Unlock()
+	log.Info().Str("job", job.id).Str("impl", job.impl).Dur("timeout", job.timeout).Msg("job submitted")
+
+	w.Header().Set("Location", "/jobs/"+job.id)
+	writeJsonResponse(w, http.StatusAccepted, job.getStatus())
+}
+
+// Handle the requests on a job.
+func (server *SolverServer) handleJob(w http.ResponseWriter, r *http.Request) {
+	id, resource, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/")
+	server.mutex.Lock()
+	job, exists := server.jobs[id]
+	server.mutex.Unlock()
+	if !exists {
+		writeJsonError(w, http.StatusNotFound, fmt.Errorf("unknown job %q", id))
+		return
+	}
+
+	// Check if the events are requested.
+	if resource == "events" {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeJsonError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+		server.streamJobEvents(w, r, job)
+		return
+	} else if resource != "" {
+		writeJsonError(w, http.StatusNotFound, fmt.Errorf("unknown job resource %q", resource))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJsonResponse(w, http.StatusOK, job.getStatus())
+	case http.MethodDelete:
+		job.stop()
+		server.dequeueJob(job)
+		log.Info().Str("job", job.id).Msg("job cancellation requested")
+		writeJsonResponse(w, http.StatusOK, job.getStatus())
+	default:
+		w.Header().Set("Allow", http.MethodGet+", "+http.MethodDelete)
+		writeJsonError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+// Stream the events of a job as Server-Sent Events until it's finished or the client is disconnected. The current
+// state is sent first as a "progress" event, then every second along with the other events.
+func (server *SolverServer) streamJobEvents(w http.ResponseWriter, r *http.Request, job *Job) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJsonError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	// Closure function writing an event, returns false if the client is disconnected.
+	writeEvent := func(event *MachineEvent) bool {
+		eventBytes, err := json.Marshal(event)
+		if err == nil {
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Event, eventBytes)
+		}
+		if err != nil {
+			log.Debug().Err(err).Str("job", job.id).Msg("unable to write the event")
+			return false
+		}
+		flusher.Flush()
+		return true
+	}
+
+	// Only the result is sent if the job is already finished.
+	subscriber := job.subscribe()
+	if subscriber == nil {
+		writeEvent(job.newEvent("result"))
+		return
+	}
+	defer job.unsubscribe(subscriber)
+	if !writeEvent(job.newEvent("progress")) {
+		return
+	}
+
+	ticker := time.NewTicker(machineProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case event, ok := <-subscriber:
+			if !ok {
+				// The job is finished, the result has been sent.
+				return
+			}
+			// The stream ends with the result, no progress event must follow it.
+			if !writeEvent(event) || event.Event == "result" {
+				return
+			}
+		case <-ticker.C:
+			if !writeEvent(job.newEvent("progress")) {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-server.ctx.Done():
+			return
+		}
+	}
+}
+
+// Returns a new job from a submission request.
+func (server *SolverServer) newJob(w http.ResponseWriter, r *http.Request) (*Job, error) {
+	// Get the implementation and the timeout.
+	query := r.URL.Query()
+	impl := query.Get("impl")
+	if impl == "" {
+		impl = "deep-search"
+	}
+	if _, exists := implementations[impl]; !exists {
+		return nil, fmt.Errorf("invalid algorithm implementation %q, available ones are %v", impl, getImplementationNames())
+	}
+	timeout := server.defaultTimeout
+	if timeoutParam := query.Get("timeout"); timeoutParam != "" {
+		timeoutSec, err := strconv.Atoi(timeoutParam)
+		if err != nil || timeoutSec <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q, expected a positive number of seconds", timeoutParam)
+		}
+		timeout = time.Duration(timeoutSec) * time.Second
+	}
+	if timeout > server.maxTimeout {
+		return nil, fmt.Errorf("invalid timeout %s, the maximum is %s", timeout, server.maxTimeout)
+	}
+
+	// Parse the board, as JSON or CSV depending on the content type.
+	body := http.MaxBytesReader(w, r.Body, serverMaxBoardSize)
+	var board *Board
+	var err error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		board, err = parseJsonBoard(body)
+	} else {
+		board, err = parseCsvBoard(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate a random ID.
+	idBytes := make([]byte, 8)
+	if _, err := rand.Read(idBytes); err != nil {
+		return nil, fmt.Errorf("unable to generate the job ID: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(server.ctx)
+	return &Job{
+		id:      hex.EncodeToString(idBytes),
+		board:   board,
+		impl:    impl,
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+		stats:   &SolverStats{},
+		status:  jobStatusQueued,
+	}, nil
+}
+
+// Remove the jobs finished for longer than the retention duration.
+func (server *SolverServer) purgeJobs() {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	for id, job := range server.jobs {
+		if job.isExpired(server.retention) {
+			delete(server.jobs, id)
+		}
+	}
+}
+
+// Write a value as the JSON body of a response.
+func writeJsonResponse(w http.ResponseWriter, statusCode int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		log.Warn().Err(err).Msg("unable to write the response")
+	}
+}
+
+// Write an error as the JSON body of a response.
+func writeJsonError(w http.ResponseWriter, statusCode int, err error) {
+	writeJsonResponse(w, statusCode, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSolverServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := newSolverServer(ctx, &SolverConfig{cacheMb: 1}, 1, 10, 10*time.Second, time.Minute, time.Hour)
+	httpServer := httptest.NewServer(server.handler())
+	defer httpServer.Close()
+
+	// Closure function executing a request and decoding the job status.
+	doRequest := func(method, path, contentType, body string, expectedStatusCode int) *JobStatus {
+		request, err := http.NewRequest(method, httpServer.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		request.Header.Set("Content-Type", contentType)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		if response.StatusCode != expectedStatusCode {
+			t.Fatalf("unexpected status code for %s %s, expected=%d, actual=%d", method, path, expectedStatusCode, response.StatusCode)
+		}
+		var status JobStatus
+		if err := json.NewDecoder(response.Body).Decode(&status); err != nil {
+			t.Fatal(err)
+		}
+		return &status
+	}
+
+	// Submit a JSON board and wait for the job to be finished.
+	status := doRequest(http.MethodPost, "/jobs?impl=astar", "application/json", `{"rows": 2, "cols": 2, "cells": [[0, 1], [1, 2]]}`, http.StatusAccepted)
+	for i := 0; status.Status == jobStatusQueued || status.Status == jobStatusRunning; i++ {
+		if i == 100 {
+			t.Fatal("the job should be finished")
+		}
+		time.Sleep(10 * time.Millisecond)
+		status = doRequest(http.MethodGet, "/jobs/"+status.ID, "", "", http.StatusOK)
+	}
+	if status.Status != jobStatusFinished || status.Result.StepCount != 2 || !status.Result.OptimalProven {
+		t.Fatalf("unexpected job status %+v, result %+v", status, status.Result)
+	}
+
+	// Submit a CSV board and cancel it.
+	status = doRequest(http.MethodPost, "/jobs?impl=deep-search&timeout=10", "text/csv", "0,1,2\n1,2,0\n2,0,1\n", http.StatusAccepted)
+	status = doRequest(http.MethodDelete, "/jobs/"+status.ID, "", "", http.StatusOK)
+	for i := 0; status.Status != jobStatusCancelled && status.Status != jobStatusFinished; i++ {
+		if i == 100 {
+			t.Fatal("the job should be cancelled")
+		}
+		time.Sleep(10 * time.Millisecond)
+		status = doRequest(http.MethodGet, "/jobs/"+status.ID, "", "", http.StatusOK)
+	}
+
+	// Invalid requests.
+	doRequest(http.MethodGet, "/jobs/unknown", "", "", http.StatusNotFound)
+	doRequest(http.MethodPost, "/jobs?impl=unknown", "text/csv", "0,1\n1,0\n", http.StatusBadRequest)
+	doRequest(http.MethodPost, "/jobs?timeout=3600", "text/csv", "0,1\n1,0\n", http.StatusBadRequest)
+	doRequest(http.MethodPost, "/jobs", "application/json", "0,1\n1,0\n", http.StatusBadRequest)
+	doRequest(http.MethodGet, "/jobs", "", "", http.StatusMethodNotAllowed)
+}
+
+func TestSolverServerEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := newSolverServer(ctx, &SolverConfig{cacheMb: 1}, 1, 10, 10*time.Second, time.Minute, time.Hour)
+	httpServer := httptest.NewServer(server.handler())
+	defer httpServer.Close()
+
+	// Closure function submitting a board and returning the job ID.
+	submitJob := func(timeoutSec int) string {
+		board, err := generateBoard("random", 30, 30, 6, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		boardCsv, err := serializeBoardToCsv(board)
+		if err != nil {
+			t.Fatal(err)
+		}
+		url := fmt.Sprintf("%s/jobs?impl=deep-search&timeout=%d", httpServer.URL, timeoutSec)
+		response, err := http.Post(url, "text/csv", strings.NewReader(boardCsv))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		var status JobStatus
+		if err := json.NewDecoder(response.Body).Decode(&status); err != nil {
+			t.Fatal(err)
+		}
+		return status.ID
+	}
+
+	// Closure function streaming the events of a job until it's finished.
+	streamEvents := func(id string) []*MachineEvent {
+		response, err := http.Get(httpServer.URL + "/jobs/" + id + "/events")
+		if err != nil {
+			t.Error(err)
+			return nil
+		}
+		defer response.Body.Close()
+		var events []*MachineEvent
+		scanner := bufio.NewScanner(response.Body)
+		scanner.Buffer(nil, 1024*1024)
+		for scanner.Scan() {
+			if data := strings.TrimPrefix(scanner.Text(), "data: "); data != scanner.Text() {
+				var event MachineEvent
+				if err := json.Unmarshal([]byte(data), &event); err != nil {
+					t.Error(err)
+					return nil
+				}
+				events = append(events, &event)
+			}
+		}
+		return events
+	}
+
+	// The first job runs until its timeout, the second one is queued and cancelled.
+	runningId := submitJob(1)
+	queuedId := submitJob(10)
+	queuedEvents := make(chan []*MachineEvent)
+	go func() {
+		queuedEvents <- streamEvents(queuedId)
+	}()
+	runningEvents := streamEvents(runningId)
+	if len(runningEvents) < 2 || runningEvents[0].Event != "progress" {
+		t.Fatalf("unexpected events of the running job %s", formatMachineEvents(runningEvents))
+	}
+	lastEvent := runningEvents[len(runningEvents)-1]
+	if lastEvent.Event != "result" || lastEvent.Status != jobStatusFinished || lastEvent.Result == nil || lastEvent.StepCount == 0 {
+		t.Fatalf("unexpected last event of the running job %s", formatMachineEvents([]*MachineEvent{lastEvent}))
+	}
+
+	// Cancel the second job, which is now running or about to run, its stream ends with the result.
+	request, _ := http.NewRequest(http.MethodDelete, httpServer.URL+"/jobs/"+queuedId, nil)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	events := <-queuedEvents
+	if len(events) < 2 || events[len(events)-1].Event != "result" || events[len(events)-1].Status != jobStatusCancelled {
+		t.Fatalf("unexpected events of the cancelled job %s", formatMachineEvents(events))
+	}
+}
+
+// Returns the main fields of events, to be printed in the failure messages.
+func formatMachineEvents(events []*MachineEvent) string {
+	var formattedEvents []string
+	for _, event := range events {
+		formattedEvents = append(formattedEvents, fmt.Sprintf("{event=%s status=%s step-count=%d result=%t}",
+			event.Event, event.Status, event.StepCount, event.Result != nil))
+	}
+	return "[" + strings.Join(formattedEvents, " ") + "]"
+}
+
+func TestSolverServerQueue(t *testing.T) {
+	// Without worker, the jobs remain queued.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := newSolverServer(ctx, &SolverConfig{cacheMb: 1}, 0, 1, 10*time.Second, time.Minute, time.Hour)
+	handler := server.handler()
+
+	// Closure function executing a request and returning the response.
+	doRequest := func(method, path, body string, expectedStatusCode int) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		if recorder.Code != expectedStatusCode {
+			t.Fatalf("unexpected status code for %s %s, expected=%d, actual=%d", method, path, expectedStatusCode, recorder.Code)
+		}
+		return recorder
+	}
+
+	// The queue is full after the first job, a cancelled job frees its slot.
+	location := doRequest(http.MethodPost, "/jobs", "0,1\n1,0\n", http.StatusAccepted).Header().Get("Location")
+	doRequest(http.MethodPost, "/jobs", "0,1\n1,0\n", http.StatusServiceUnavailable)
+	doRequest(http.MethodDelete, location, "", http.StatusOK)
+	doRequest(http.MethodPost, "/jobs", "0,1\n1,0\n", http.StatusAccepted)
+	doRequest(http.MethodPost, "/jobs", "0,1\n1,0\n", http.StatusServiceUnavailable)
+}
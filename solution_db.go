@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SolutionRecord is the best known solution of a board stored in the solution database, its fields are exported to
+// be serialized.
+type SolutionRecord struct {
+	// Fingerprint of the board, see Board.fingerprint, and its name if known.
+	Board string `json:"board"`
+	Name  string `json:"name,omitempty"`
+
+	// The best known solution and its step count.
+	Steps     []int `json:"steps"`
+	StepCount int   `json:"step-count"`
+
+	// Whether the solution has been proven optimal.
+	OptimalProven bool `json:"optimal-proven"`
+
+	// Name of the implementation which found the solution, and when.
+	Implementation string    `json:"implementation"`
+	UpdatedAt      time.Time `json:"updated-at"`
+}
+
+// SolutionDatabase stores the best known solution of the boards, as a directory containing a JSON file per board named
+// after the board fingerprint, see SolutionRecord.
+type SolutionDatabase struct {
+	dirPath string
+}
+
+// Returns the solution database stored in a directory, the directory is created if needed.
+func openSolutionDatabase(dirPath string) (*SolutionDatabase, error) {
+	if err := os.MkdirAll(dirPath, 0755); err != nil {
+		return nil, fmt.Errorf("unable to create the solution database directory: %w", err)
+	}
+	return &SolutionDatabase{dirPath: dirPath}, nil
+}
+
+// Returns the path of the file of the record of a board.
+func (db *SolutionDatabase) getRecordPath(board *Board) string {
+	return filepath.Join(db.dirPath, board.fingerprint()+".json")
+}
+
+// Returns the record of a board, nil if the board is unknown.
+// A record whose solution does not solve the board, e.g. a modified file, is an error.
+func (db *SolutionDatabase) get(board *Board) (*SolutionRecord, error) {
+	recordBytes, err := os.ReadFile(db.getRecordPath(board))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read the solution database record: %w", err)
+	}
+
+	var record SolutionRecord
+	if err := json.Unmarshal(recordBytes, &record); err != nil {
+		return nil, fmt.Errorf("unable to parse the solution database record: %w", err)
+	}
+	if report := verifySolution(board.clone(), record.Steps); !report.solved {
+		return nil, fmt.Errorf("invalid solution database record, the solution does not solve the board")
+	}
+	return &record, nil
+}
+
+// Store the solution of a board if it improves the known one, i.e. if it has less steps or if it has been proven
+// optimal. Returns whether the record has been updated.
+func (db *SolutionDatabase) update(board *Board, solution []int, optimalProven bool, impl string) (bool, error) {
+	if solution == nil {
+		return false, nil
+	}
+	record, err := db.get(board)
+	if err != nil {
+		return false, err
+	}
+	if record != nil && (len(solution) > record.StepCount ||
+		(len(solution) == record.StepCount && (record.OptimalProven || !optimalProven))) {
+		return false, nil
+	}
+
+	record = &SolutionRecord{
+		Board:          board.fingerprint(),
+		Name:           board.name,
+		Steps:          solution,
+		StepCount:      len(solution),
+		OptimalProven:  optimalProven,
+		Implementation: impl,
+		UpdatedAt:      time.Now().UTC(),
+	}
+	err = writeFileAtomically(db.getRecordPath(board), func(writer io.Writer) error {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(record)
+	})
+	if err != nil {
+		return false, fmt.Errorf("unable to write the solution database record: %w", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSolutionDatabase(t *testing.T) {
+	db, err := openSolutionDatabase(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	board := NewBoard(2, 2, []int{0, 1, 1, 2})
+	if record, err := db.get(board); err != nil || record != nil {
+		t.Fatal("the board should be unknown")
+	}
+
+	// Closure function updating the database and checking whether the solution has been stored.
+	checkUpdate := func(solution []int, optimalProven, expected bool) {
+		updated, err := db.update(board, solution, optimalProven, "dummy")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated != expected {
+			t.Fatalf("unexpected update for solution=%v, optimal-proven=%v, expected=%v", solution, optimalProven, expected)
+		}
+	}
+	checkUpdate([]int{2, 1, 2}, false, true)
+	checkUpdate([]int{1, 2, 1}, false, false)
+	checkUpdate([]int{1, 2}, false, true)
+	checkUpdate([]int{1, 2}, true, true)
+	checkUpdate([]int{1, 2}, false, false)
+
+	record, err := db.get(board)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.StepCount != 2 || !record.OptimalProven || record.Board != board.fingerprint() {
+		t.Fatalf("unexpected record %+v", record)
+	}
+
+	// A record whose solution does not solve the board is rejected.
+	if err := os.WriteFile(db.getRecordPath(board), []byte(`{"steps": [1], "step-count": 1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.get(board); err == nil {
+		t.Fatal("the invalid record should be rejected")
+	}
+}
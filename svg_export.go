@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// Replay a solution on a board and returns the step at which each cell has been integrated in the completed area, 0
+// for the initial completed area and -1 for the cells not integrated if the solution does not solve the board.
+func computeCellSteps(board *Board, solution []int) ([]int, error) {
+	cellSteps := make([]int, len(board.cells))
+	for cellId := range cellSteps {
+		cellSteps[cellId] = -1
+	}
+	board.completedCells.forEach(func(cellId int) {
+		cellSteps[cellId] = 0
+	})
+
+	// The cells integrated at each step are given by the same traversal as playStep.
+	board = board.clone()
+	for i, color := range solution {
+		if color < 0 || color >= board.nbColors {
+			return nil, fmt.Errorf("invalid color %d at step #%d", color, i+1)
+		}
+		for _, cellId := range board.playStepAndGetCompletedCells(color) {
+			cellSteps[cellId] = i + 1
+		}
+	}
+	return cellSteps, nil
+}
+
+// Write the SVG drawing of a board and of the replay of a solution:
+//   - the cells of the initial board, drawn with the palette colors
+//   - the step at which each region of the initial board is integrated in the completed area, as a label
+//   - the outline of the completed area after each step, i.e. the line between the completed area and its frontier
+func writeSolutionSvg(writer io.Writer, board *Board, solution []int, palette color.Palette, cellSize int) error {
+	if len(palette) < board.nbColors {
+		return fmt.Errorf("the palette contains %d colors, the board requires %d", len(palette), board.nbColors)
+	}
+	cellSteps, err := computeCellSteps(board, solution)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(writer)
+	width := board.nbCols * cellSize
+	height := board.nbRows * cellSize
+	fmt.Fprintf(out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", width, height, width, height)
+
+	// Draw the cells.
+	fmt.Fprintln(out, `<g id="cells" shape-rendering="crispEdges">`)
+	for cellId, cellColor := range board.cells {
+		fmt.Fprintf(out, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+			(cellId%board.nbCols)*cellSize, (cellId/board.nbCols)*cellSize, cellSize, cellSize, formatHexColor(palette[cellColor]))
+	}
+	fmt.Fprintln(out, `</g>`)
+
+	// Draw the outline of the completed area after each step.
+	fmt.Fprintln(out, `<g id="frontiers" fill="none" stroke="#000" stroke-opacity="0.5" stroke-linecap="square">`)
+	for step := 0; step <= len(solution); step++ {
+		isCompleted := func(cellId int) bool {
+			return cellSteps[cellId] >= 0 && cellSteps[cellId] <= step
+		}
+
+		// Get the cell edges separating a completed cell from a not completed one.
+		var path strings.Builder
+		for cellId := range board.cells {
+			if !isCompleted(cellId) {
+				continue
+			}
+			row := cellId / board.nbCols
+			col := cellId % board.nbCols
+			x := col * cellSize
+			y := row * cellSize
+
+			// Top
+			if row > 0 && !isCompleted(cellId-board.nbCols) {
+				fmt.Fprintf(&path, "M%d %dh%d", x, y, cellSize)
+			}
+
+			// Bottom
+			if row < (board.nbRows-1) && !isCompleted(cellId+board.nbCols) {
+				fmt.Fprintf(&path, "M%d %dh%d", x, y+cellSize, cellSize)
+			}
+
+			// Left
+			if col > 0 && !isCompleted(cellId-1) {
+				fmt.Fprintf(&path, "M%d %dv%d", x, y, cellSize)
+			}
+
+			// Right
+			if col < (board.nbCols-1) && !isCompleted(cellId+1) {
+				fmt.Fprintf(&path, "M%d %dv%d", x+cellSize, y, cellSize)
+			}
+		}
+		if path.Len() > 0 {
+			fmt.Fprintf(out, `<path data-step="%d" d="%s"><title>Frontier after step %d</title></path>`+"\n", step, path.String(), step)
+		}
+	}
+	fmt.Fprintln(out, `</g>`)
+
+	// Label each region of the initial board with its step, at the region cell closest to the region center.
+	// All the cells of a region are integrated in the completed area at the same step.
+	graph := newRegionGraph(board)
+	regionCells := make([][]int, graph.getRegionCount())
+	for cellId, regionId := range graph.cellRegions {
+		regionCells[regionId] = append(regionCells[regionId], cellId)
+	}
+	fmt.Fprintf(out, `<g id="steps" font-family="sans-serif" font-size="%d" text-anchor="middle" dominant-baseline="central" fill="#000">`+"\n", cellSize/2)
+	for _, cells := range regionCells {
+		step := cellSteps[cells[0]]
+		if step < 0 {
+			continue
+		}
+
+		// Compute the center of the region, multiplied by its cell count.
+		rowSum, colSum := 0, 0
+		for _, cellId := range cells {
+			rowSum += cellId / board.nbCols
+			colSum += cellId % board.nbCols
+		}
+		labelCellId := cells[0]
+		bestDistance := -1
+		for _, cellId := range cells {
+			rowDistance := (cellId/board.nbCols)*len(cells) - rowSum
+			colDistance := (cellId%board.nbCols)*len(cells) - colSum
+			distance := rowDistance*rowDistance + colDistance*colDistance
+			if bestDistance == -1 || distance < bestDistance {
+				bestDistance = distance
+				labelCellId = cellId
+			}
+		}
+
+		fmt.Fprintf(out, `<text x="%d" y="%d">%d</text>`+"\n",
+			(labelCellId%board.nbCols)*cellSize+cellSize/2, (labelCellId/board.nbCols)*cellSize+cellSize/2, step)
+	}
+	fmt.Fprintln(out, `</g>`)
+
+	fmt.Fprintln(out, `</svg>`)
+	return out.Flush()
+}
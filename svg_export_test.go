@@ -0,0 +1,56 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestComputeCellSteps(t *testing.T) {
+	board := NewBoard(2, 2, []int{0, 1, 1, 2})
+	cellSteps, err := computeCellSteps(board, []int{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cellSteps, []int{0, 1, 1, 2}) {
+		t.Fatalf("unexpected cell steps %v", cellSteps)
+	}
+
+	// The cells integrated at each step must match the area gained reported by the verifier.
+	board, err = readInputFile("samples/12_12_6-1.csv", &InputOptions{checkSquare: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution := []int{0, 2, 4, 5, 3, 1, 0, 5, 2, 3, 0, 5, 4, 2, 3, 5, 4, 0, 1}
+	cellSteps, err = computeCellSteps(board, solution)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stepCellCounts := make([]int, len(solution)+1)
+	for _, step := range cellSteps {
+		stepCellCounts[step]++
+	}
+	report := verifySolution(board.clone(), solution)
+	for i, step := range report.steps {
+		if stepCellCounts[i+1] != step.areaGained {
+			t.Fatalf("step %d: %d cells, expected %d", i+1, stepCellCounts[i+1], step.areaGained)
+		}
+	}
+}
+
+func TestWriteSolutionSvg(t *testing.T) {
+	board := NewBoard(2, 2, []int{0, 1, 1, 2})
+	var builder strings.Builder
+	if err := writeSolutionSvg(&builder, board, []int{1, 2}, defaultImagePalette, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// 4 cells, 4 regions labelled with their step and 2 frontier outlines (none once solved).
+	svg := builder.String()
+	if strings.Count(svg, "<rect") != 4 || strings.Count(svg, "<text") != 4 || strings.Count(svg, "<path") != 2 {
+		t.Fatalf("unexpected SVG:\n%s", svg)
+	}
+	if !strings.Contains(svg, `<text x="15" y="5">1</text>`) {
+		t.Fatalf("missing step label:\n%s", svg)
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Default size in kilobytes of the transposition tables per board cell, and maximum default size in megabytes.
+const defaultTranspositionTableSizeKbPerCell = 256
+const maxDefaultTranspositionTableSizeMb = 256
+
+// Number of locks protecting the buckets of a transposition table, each lock protecting 1 bucket out of N.
+const transpositionTableLockCount = 1024
+
+// TranspositionEntry is an entry of a transposition table, i.e. a board configuration already processed.
+type TranspositionEntry struct {
+	// The Zobrist hash of the board configuration, see Board.hash.
+	hash uint64
+
+	// The verification hash of the board configuration, used to detect the hash collisions.
+	verificationHash uint64
+
+	// The minimum step count at which this board configuration has been evaluated, plus one so that the zero
+	// value means an empty entry.
+	stepCountPlusOne int32
+}
+
+// TranspositionBucket is a bucket of a transposition table, it contains two entries:
+//   - the "depth-preferred" one, only replaced by an entry with a lower or equal step count, i.e. by a board
+//     configuration closer to the root of the search tree and thus more costly to evaluate again
+//   - the "always-replace" one, replaced by all the other entries
+type TranspositionBucket struct {
+	depthPreferred TranspositionEntry
+	alwaysReplace  TranspositionEntry
+}
+
+// TranspositionTable is a fixed size cache of the board configurations already processed, indexed by their hash.
+// When full, the entries are replaced according to the policy of the buckets, see TranspositionBucket.
+// It's safe for concurrent use by multiple Goroutines.
+type TranspositionTable struct {
+	// The buckets, their count is a power of 2 so that the index of the bucket of a hash is given by its lower bits.
+	buckets []TranspositionBucket
+	mask    uint64
+
+	// Whether to verify that the entries are about the same board configuration using the verification hash.
+	verify bool
+
+	// Locks protecting the buckets.
+	locks [transpositionTableLockCount]sync.Mutex
+
+	// Statistics.
+	entryCounter     atomic.Int64
+	hitCounter       atomic.Int64
+	collisionCounter atomic.Int64
+	evictionCounter  atomic.Int64
+}
+
+// Returns the size in megabytes of the transposition table used to search a board: the specified size if it's
+// positive, otherwise a default size proportional to the cell count of the board so that the small boards don't
+// allocate a large table.
+func getTranspositionTableSizeMb(sizeMb int, board *Board) int {
+	if sizeMb > 0 {
+		return sizeMb
+	}
+
+	sizeMb = (len(board.cells)*defaultTranspositionTableSizeKbPerCell + 1023) / 1024
+	if sizeMb > maxDefaultTranspositionTableSizeMb {
+		sizeMb = maxDefaultTranspositionTableSizeMb
+	}
+	return sizeMb
+}
+
+// Returns a new transposition table with the largest power of 2 bucket count fitting in the specified size.
+func newTranspositionTable(sizeMb int, verify bool) *TranspositionTable {
+	bucketCount := uint64(1)
+	maxBucketCount := uint64(sizeMb) * 1024 * 1024 / uint64(unsafe.Sizeof(TranspositionBucket{}))
+	for bucketCount*2 <= maxBucketCount {
+		bucketCount *= 2
+	}
+
+	return &TranspositionTable{
+		buckets: make([]TranspositionBucket, bucketCount),
+		mask:    bucketCount - 1,
+		verify:  verify,
+	}
+}
+
+// Check if a board configuration has already been processed with a lower or equal step count; if not, the board is
+// stored as processed at the specified step count and true is returned.
+func (table *TranspositionTable) markProcessed(board *Board, stepCount int) bool {
+	index := board.hash & table.mask
+	lock := &table.locks[index%transpositionTableLockCount]
+	lock.Lock()
+	defer lock.Unlock()
+
+	bucket := &table.buckets[index]
+	newEntry := TranspositionEntry{
+		hash:             board.hash,
+		verificationHash: board.verificationHash,
+		stepCountPlusOne: int32(stepCount + 1),
+	}
+
+	// Check if one of the entries is about this board configuration.
+	for _, entry := range []*TranspositionEntry{&bucket.depthPreferred, &bucket.alwaysReplace} {
+		if entry.stepCountPlusOne == 0 || entry.hash != board.hash {
+			continue
+		}
+
+		if table.verify && entry.verificationHash != board.verificationHash {
+			// Hash collision, the entry is about another board configuration.
+			table.collisionCounter.Add(1)
+			continue
+		}
+
+		if entry.stepCountPlusOne <= newEntry.stepCountPlusOne {
+			// Already processed with a lower or equal step count.
+			table.hitCounter.Add(1)
+			return false
+		}
+
+		// Already processed but with a greater step count, the entry is updated.
+		*entry = newEntry
+		return true
+	}
+
+	// Store the new entry, the replaced one is moved to the "always-replace" entry if it has a greater step count.
+	if bucket.depthPreferred.stepCountPlusOne == 0 || newEntry.stepCountPlusOne <= bucket.depthPreferred.stepCountPlusOne {
+		bucket.depthPreferred, newEntry = newEntry, bucket.depthPreferred
+		if newEntry.stepCountPlusOne == 0 {
+			table.entryCounter.Add(1)
+			return true
+		}
+	}
+	if bucket.alwaysReplace.stepCountPlusOne == 0 {
+		table.entryCounter.Add(1)
+	} else {
+		table.evictionCounter.Add(1)
+	}
+	bucket.alwaysReplace = newEntry
+
+	return true
+}
+
+// Remove all the entries from the table.
+func (table *TranspositionTable) reset() {
+	for i := range table.locks {
+		table.locks[i].Lock()
+	}
+	defer func() {
+		for i := range table.locks {
+			table.locks[i].Unlock()
+		}
+	}()
+
+	for i := range table.buckets {
+		table.buckets[i] = TranspositionBucket{}
+	}
+	table.entryCounter.Store(0)
+}
+
+// Size in bytes of a serialized transposition entry: the two hashes and the step count.
+const transpositionEntrySerializedSize = 8 + 8 + 4
+
+// Write the entries of the table, preceded by the bucket count. The table must not be modified concurrently.
+func (table *TranspositionTable) writeTo(writer io.Writer) error {
+	bufferedWriter := bufio.NewWriter(writer)
+	if err := binary.Write(bufferedWriter, binary.LittleEndian, uint64(len(table.buckets))); err != nil {
+		return err
+	}
+
+	buffer := make([]byte, transpositionEntrySerializedSize)
+	for i := range table.buckets {
+		for _, entry := range []*TranspositionEntry{&table.buckets[i].depthPreferred, &table.buckets[i].alwaysReplace} {
+			binary.LittleEndian.PutUint64(buffer, entry.hash)
+			binary.LittleEndian.PutUint64(buffer[8:], entry.verificationHash)
+			binary.LittleEndian.PutUint32(buffer[16:], uint32(entry.stepCountPlusOne))
+			if _, err := bufferedWriter.Write(buffer); err != nil {
+				return err
+			}
+		}
+	}
+	return bufferedWriter.Flush()
+}
+
+// Replace the entries of the table by the ones previously written by writeTo. The bucket count must be the same,
+// i.e. the tables must have the same size. The table must not be used concurrently.
+func (table *TranspositionTable) readFrom(reader io.Reader) error {
+	bufferedReader := bufio.NewReader(reader)
+	var bucketCount uint64
+	if err := binary.Read(bufferedReader, binary.LittleEndian, &bucketCount); err != nil {
+		return err
+	}
+	if bucketCount != uint64(len(table.buckets)) {
+		return fmt.Errorf("invalid bucket count, expected=%d, actual=%d", len(table.buckets), bucketCount)
+	}
+
+	buffer := make([]byte, transpositionEntrySerializedSize)
+	entryCount := int64(0)
+	for i := range table.buckets {
+		for _, entry := range []*TranspositionEntry{&table.buckets[i].depthPreferred, &table.buckets[i].alwaysReplace} {
+			if _, err := io.ReadFull(bufferedReader, buffer); err != nil {
+				return err
+			}
+			entry.hash = binary.LittleEndian.Uint64(buffer)
+			entry.verificationHash = binary.LittleEndian.Uint64(buffer[8:])
+			entry.stepCountPlusOne = int32(binary.LittleEndian.Uint32(buffer[16:]))
+			if entry.stepCountPlusOne != 0 {
+				entryCount++
+			}
+		}
+	}
+	table.entryCounter.Store(entryCount)
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTranspositionTableReplacementPolicy(t *testing.T) {
+	// Table with a single bucket, all the boards are thus stored in the same one.
+	table := newTranspositionTable(1, true)
+	table.buckets = table.buckets[:1]
+	table.mask = 0
+
+	board1 := &Board{hash: 1, verificationHash: 1}
+	board2 := &Board{hash: 2, verificationHash: 2}
+	board3 := &Board{hash: 3, verificationHash: 3}
+
+	// The first board is stored in the "depth-preferred" entry and then found.
+	if !table.markProcessed(board1, 5) || table.markProcessed(board1, 5) || table.markProcessed(board1, 6) {
+		t.Fatal("board 1 should be processed only once at step count 5")
+	}
+
+	// A board with a greater step count goes to the "always-replace" entry.
+	if !table.markProcessed(board2, 8) || table.buckets[0].alwaysReplace.hash != 2 {
+		t.Fatal("board 2 should be stored in the always-replace entry")
+	}
+
+	// A board with a lower step count goes to the "depth-preferred" entry, the previous one replacing board 2.
+	if !table.markProcessed(board3, 3) || table.buckets[0].depthPreferred.hash != 3 ||
+		table.buckets[0].alwaysReplace.hash != 1 {
+		t.Fatal("board 3 should be stored in the depth-preferred entry")
+	}
+	if table.evictionCounter.Load() != 1 || table.entryCounter.Load() != 2 {
+		t.Fatal("board 2 should have been evicted")
+	}
+
+	// A board with the same hash but a different verification hash is a collision.
+	collidingBoard := &Board{hash: 3, verificationHash: 4}
+	if !table.markProcessed(collidingBoard, 10) || table.collisionCounter.Load() != 1 {
+		t.Fatal("the colliding board should not be considered as processed")
+	}
+}
+
+func TestTranspositionTableSerialization(t *testing.T) {
+	table := newTranspositionTable(1, true)
+	table.markProcessed(&Board{hash: 1, verificationHash: 10}, 5)
+	table.markProcessed(&Board{hash: 2, verificationHash: 20}, 8)
+
+	// Write the table and read it into another one.
+	var buffer bytes.Buffer
+	if err := table.writeTo(&buffer); err != nil {
+		t.Fatal(err)
+	}
+	other := newTranspositionTable(1, true)
+	if err := other.readFrom(bytes.NewReader(buffer.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if other.entryCounter.Load() != 2 || other.markProcessed(&Board{hash: 1, verificationHash: 10}, 5) ||
+		!other.markProcessed(&Board{hash: 2, verificationHash: 20}, 7) {
+		t.Fatal("the entries should have been restored")
+	}
+
+	// A table of another size is rejected.
+	if err := newTranspositionTable(2, true).readFrom(bytes.NewReader(buffer.Bytes())); err == nil {
+		t.Fatal("the table of another size should be rejected")
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+// VerificationReport contains the result of the replay of a solution on a board.
+type VerificationReport struct {
+	// Whether the board is solved at the end of the solution.
+	solved bool
+
+	// Number of steps after which the board is solved, -1 if it's not.
+	solvedStepCount int
+
+	// Number of steps after which the completed area stops growing, i.e. the index of the first step of the useless
+	// suffix of the solution. It's equal to the solution step count if the last step is useful.
+	stallStepCount int
+
+	// Number of cells not in the completed area at the end of the solution.
+	remainingCellCount int
+
+	// Details of each step of the solution.
+	steps []VerificationStep
+}
+
+// VerificationStep contains the details of the replay of a solution step.
+type VerificationStep struct {
+	// The color played.
+	color int
+
+	// Whether the color is valid, i.e. is one of the board colors.
+	valid bool
+
+	// Whether the step is a no-op, i.e. the color is not in the frontier.
+	noOp bool
+
+	// Number of cells added to the completed area by this step.
+	areaGained int
+
+	// Number of cells in the completed area after this step.
+	completedCount int
+}
+
+// Replay a solution on a board and return the verification report. The board is modified.
+func verifySolution(board *Board, solution []int) *VerificationReport {
+	report := &VerificationReport{
+		solvedStepCount: -1,
+		steps:           make([]VerificationStep, 0, len(solution)),
+	}
+	if board.isSolved() {
+		report.solvedStepCount = 0
+	}
+
+	for i, color := range solution {
+		step := VerificationStep{
+			color: color,
+			valid: color >= 0 && color < board.nbColors,
+		}
+
+		// Check if the color is in the frontier.
+		step.noOp = true
+		if step.valid {
+			for _, frontierColor := range board.getColorsInFrontier() {
+				if color == frontierColor {
+					step.noOp = false
+					break
+				}
+			}
+		}
+
+		// Play the step, an invalid color being ignored.
+		previousCompletedCount := board.completedCount
+		if step.valid {
+			board.playStep(color)
+		}
+		step.areaGained = board.completedCount - previousCompletedCount
+		step.completedCount = board.completedCount
+		report.steps = append(report.steps, step)
+
+		if step.areaGained > 0 {
+			report.stallStepCount = i + 1
+		}
+		if report.solvedStepCount == -1 && board.isSolved() {
+			report.solvedStepCount = i + 1
+		}
+	}
+
+	report.solved = board.isSolved()
+	report.remainingCellCount = len(board.cells) - board.completedCount
+
+	return report
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestVerifySolution(t *testing.T) {
+	solution := []int{1, 0, 3, 1, 3, 0, 1, 0, 2, 3, 1, 0}
+
+	// The complete solution solves the board.
+	board, err := readInputFile("samples/12_12_4-1.csv", &InputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := verifySolution(board, solution)
+	if !report.solved || report.solvedStepCount != len(solution) || report.remainingCellCount != 0 {
+		t.Fatalf("the solution should solve the board: %+v", report)
+	}
+
+	// A truncated solution with a no-op and an invalid step does not.
+	board, err = readInputFile("samples/12_12_4-1.csv", &InputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report = verifySolution(board, []int{1, 1, 42})
+	if report.solved || report.stallStepCount != 1 || report.remainingCellCount == 0 {
+		t.Fatalf("the truncated solution should not solve the board: %+v", report)
+	}
+	if !report.steps[1].noOp || report.steps[1].areaGained != 0 || report.steps[2].valid {
+		t.Fatalf("the second step should be a no-op and the third one invalid: %+v", report.steps)
+	}
+}
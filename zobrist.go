@@ -0,0 +1,37 @@
+package main
+
+import "math/rand"
+
+// Seeds of the random number generators used to create the Zobrist keys. They are constant so that the hash of a
+// board configuration is the same across executions.
+const (
+	zobristSeed             = 0x636f6c6f72
+	zobristVerificationSeed = 0x6974
+)
+
+// ZobristKeys contains the random keys used to compute the Zobrist hash of the configurations of a board.
+// A board configuration is fully defined by its completed area and the color of the latter (the other cells never
+// change), so its hash is the XOR of the keys of the completed cells and of the key of the completed area color.
+// It can thus be updated incrementally when a cell is completed or when the color is changed.
+type ZobristKeys struct {
+	// Keys of the cells, indexed by the cell ID.
+	cellKeys []uint64
+
+	// Keys of the completed area colors, indexed by the color.
+	colorKeys []uint64
+}
+
+func newZobristKeys(nbCells, nbColors int, seed int64) *ZobristKeys {
+	random := rand.New(rand.NewSource(seed))
+	keys := &ZobristKeys{
+		cellKeys:  make([]uint64, nbCells),
+		colorKeys: make([]uint64, nbColors),
+	}
+	for i := range keys.cellKeys {
+		keys.cellKeys[i] = random.Uint64()
+	}
+	for i := range keys.colorKeys {
+		keys.colorKeys[i] = random.Uint64()
+	}
+	return keys
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestZobristHashIsIncremental(t *testing.T) {
+	board, err := readInputFile("samples/20_20_5-1.csv", &InputOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Play random colors and check that the incrementally updated hashes are the same as the ones of a new board
+	// created from the current cells.
+	random := rand.New(rand.NewSource(1))
+	for step := 0; !board.isSolved(); step++ {
+		colors := board.getColorsInFrontier()
+		board.playStep(colors[random.Intn(len(colors))])
+
+		cells := make([]int, len(board.cells))
+		copy(cells, board.cells)
+		expected := NewBoard(board.nbRows, board.nbCols, cells)
+		if board.hash != expected.hash || board.verificationHash != expected.verificationHash {
+			t.Fatalf("step #%d: incremental hashes differ from the computed ones", step)
+		}
+	}
+}